@@ -1,15 +1,26 @@
 package main
 
 import (
+	"context"
+	"io"
+	stdhttp "net/http"
+
 	logger "github.com/sirupsen/logrus"
 
+	"service-user/internal/app/catalog"
+	"service-user/internal/app/consumer"
 	"service-user/internal/app/delivery/http"
+	"service-user/internal/app/delivery/middleware"
+	"service-user/internal/app/events"
 	"service-user/internal/app/repository"
 	"service-user/internal/app/service"
 	"service-user/internal/app/utils"
+	"service-user/internal/app/vault"
+	"service-user/internal/app/worker"
 	"service-user/internal/configs"
 	"service-user/internal/server"
 	"service-user/pkg/db"
+	"service-user/pkg/envelope"
 
 	logging "service-user/pkg/logger"
 )
@@ -23,6 +34,11 @@ import (
 
 // @securityDefinitions.cookie CookieAuth
 // @name access_token
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Access token в формате "Bearer <token>"
 func main() {
 	// Загружаем конфигурацию
 	cfg, err := configs.LoadConfig("./internal/configs")
@@ -39,19 +55,85 @@ func main() {
 	}
 	defer dbConn.Close()
 
-	// public key для проверки токена
-	auth, err := utils.NewJWTManager(cfg.Auth.PublicKey)
+	// Фоновые задачи работают, пока запущен сервер
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// ключи для проверки токена: JWKS сервиса авторизации и локальный public key
+	auth, err := utils.NewJWTManager(&cfg.Auth)
 	if err != nil {
 		logger.Fatalf("Error creating JWT Manager: %v", err)
 		return
 	}
+	go auth.Run(ctx)
+
+	// мастер-ключи для шифрования номеров карт
+	keys, err := envelope.NewStaticKeyProvider(cfg.Encryption.ActiveKeyID, cfg.Encryption.MasterKeys)
+	if err != nil {
+		logger.Fatalf("Error loading master keys: %v", err)
+	}
+
+	fingerprintKey, err := envelope.LoadKey(cfg.Encryption.FingerprintKey)
+	if err != nil {
+		logger.Fatalf("Error loading card fingerprint key: %v", err)
+	}
+	cardVault := vault.NewPostgresVault(dbConn, envelope.NewEncrypter(keys), fingerprintKey)
+
+	// источники access token в порядке приоритета
+	tokenSources, err := middleware.NewTokenSources(cfg.Auth.TokenSources, cfg.Auth.CookieName, cfg.Auth.HeaderName)
+	if err != nil {
+		logger.Fatalf("Error configuring token sources: %v", err)
+	}
 
 	// db.ApplyMigrations(cfg.Database.Dsn, cfg.Database.MigratePath)
 
-	repo := repository.NewRepository(dbConn)
-	services := service.NewService(repo)
-	handlers := http.NewHandler(services, auth)
+	tombstoneKey, err := envelope.LoadKey(cfg.Profiles.ErasureTombstoneKey)
+	if err != nil {
+		logger.Fatalf("Error loading erasure tombstone key: %v", err)
+	}
+
+	repo := repository.NewRepository(dbConn, cardVault, tombstoneKey)
+	productCatalog := catalog.NewHTTPCatalog(cfg.Catalog.Url, &stdhttp.Client{Timeout: cfg.Catalog.Timeout})
+	services := service.NewService(repo, productCatalog, cfg)
+	handlers := http.NewHandler(services, auth, tokenSources, middleware.NewScopeChecker(cfg.Auth.EnforceScopes))
+
+	// без списка отзыва нельзя принимать токены: сервер запускается только после его загрузки
+	if err = services.RevocationService.RefreshRevocations(ctx); err != nil {
+		logger.Fatalf("Error loading revoked tokens: %v", err)
+	}
+	// отзыв, сделанный на другом экземпляре, начинает действовать после обновления списка
+	go worker.NewRevocationSyncWorker(services.RevocationService, cfg.Auth.RevocationSync).Run(ctx)
+
+	publisher, err := events.NewPublisher(&cfg.Events)
+	if err != nil {
+		logger.Fatalf("Error creating events publisher: %v", err)
+	}
+	if closer, ok := publisher.(io.Closer); ok {
+		defer closer.Close()
+	}
+	// события профиля публикуются из outbox после фиксации транзакции, доставка at-least-once
+	go worker.NewOutboxRelayWorker(repo.OutboxRepository, publisher, &cfg.Events).Run(ctx)
+	// профили пользователей, зарегистрированных в сервисе авторизации, создаются по событиям
+	if cfg.UserEvents.Enabled {
+		reader := consumer.NewKafkaReader(cfg.Events.Kafka.Brokers, &cfg.UserEvents)
+		defer reader.Close()
+		deadLetters := events.NewKafkaWriter(&cfg.Events.Kafka)
+		defer deadLetters.Close()
+		go consumer.NewUserEventsConsumer(reader, deadLetters, services.UserEventsService, &cfg.UserEvents).Run(ctx)
+	}
+
+	go worker.NewCardExpiryWorker(repo.CardRepository, cfg.Cards.ExpiryNotifyDays, cfg.Cards.ExpiryCheckInterval).Run(ctx)
+
+	go worker.NewIdempotencyCleanupWorker(services.IdempotencyService, cfg.Idempotency.CleanupInterval).Run(ctx)
+
+	go worker.NewProfilePurgeWorker(repo.ProfileRepository, cfg.Profiles.DeleteGracePeriod, cfg.Profiles.PurgeInterval).Run(ctx)
+
+	router := handlers.InitRoutes()
+	// адрес клиента для журнала изменений берется из X-Forwarded-For только от доверенных прокси
+	if err = router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logger.Fatalf("Error configuring trusted proxies: %v", err)
+	}
 
 	// Настройка и запуск сервера
-	server.SetupAndRunServer(&cfg.Server, handlers.InitRoutes())
+	server.SetupAndRunServer(&cfg.Server, router)
 }
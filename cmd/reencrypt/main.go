@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+
+	"service-user/internal/app/vault"
+	"service-user/internal/configs"
+	"service-user/pkg/db"
+	"service-user/pkg/envelope"
+
+	logging "service-user/pkg/logger"
+)
+
+// Перешифровывает номера карт в хранилище под активный мастер-ключ и заполняет
+// отпечатки карт, в том числе перенесенных в хранилище миграцией открытыми. Запускается после
+// миграции 000005 и после смены encryption.active_key_id, старый ключ должен оставаться в master_keys.
+func main() {
+	batchSize := flag.Int("batch", 100, "количество карт, перешифровываемых в одной транзакции")
+	pause := flag.Duration("pause", 100*time.Millisecond, "пауза между пачками")
+	flag.Parse()
+
+	cfg, err := configs.LoadConfig("./internal/configs")
+	if err != nil {
+		logger.Fatalf("Error loading config: %v", err)
+	}
+	logging.SetupLogger(cfg.Logging.Level, cfg.Logging.Format, cfg.Logging.OutputFile)
+
+	dbConn, err := db.ConnectPostgres(cfg.Database.Dsn)
+	if err != nil {
+		logger.Fatalf("Database connection failed: %v", err)
+	}
+	defer dbConn.Close()
+
+	keys, err := envelope.NewStaticKeyProvider(cfg.Encryption.ActiveKeyID, cfg.Encryption.MasterKeys)
+	if err != nil {
+		logger.Fatalf("Error loading master keys: %v", err)
+	}
+	fingerprintKey, err := envelope.LoadKey(cfg.Encryption.FingerprintKey)
+	if err != nil {
+		logger.Fatalf("Error loading card fingerprint key: %v", err)
+	}
+	cardVault := vault.NewPostgresVault(dbConn, envelope.NewEncrypter(keys), fingerprintKey)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	total := 0
+	for ctx.Err() == nil {
+		n, err := cardVault.Reencrypt(ctx, *batchSize)
+		if err != nil {
+			logger.Fatalf("Re-encryption failed after %d cards: %v", total, err)
+		}
+		total += n
+		if n > 0 {
+			logger.Infof("Re-encrypted %d cards", total)
+		} else {
+			// Пустая пачка не значит, что все готово: заблокированные строки пропускаются
+			pending, err := cardVault.PendingReencryption(ctx)
+			if err != nil {
+				logger.Fatalf("Re-encryption failed after %d cards: %v", total, err)
+			}
+			if pending == 0 {
+				break
+			}
+			logger.Infof("Waiting for %d locked cards", pending)
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(*pause):
+		}
+	}
+
+	logger.Infof("Re-encryption finished, %d cards processed", total)
+}
@@ -0,0 +1,74 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	logger "github.com/sirupsen/logrus"
+
+	"service-user/internal/app/errs"
+)
+
+// Catalog - источник цен товаров. Цена в корзине всегда берется из каталога, а не из запроса клиента
+type Catalog interface {
+	// ProductPrice возвращает текущую цену товара
+	ProductPrice(ctx context.Context, productID uuid.UUID) (float64, error)
+}
+
+type product struct {
+	ID    uuid.UUID `json:"id"`
+	Price float64   `json:"price"`
+}
+
+// HTTPCatalog - каталог товаров, запрашиваемый у сервиса каталога по GET {url}/products/{id}
+type HTTPCatalog struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPCatalog(url string, client *http.Client) *HTTPCatalog {
+	return &HTTPCatalog{
+		url:    strings.TrimSuffix(url, "/"),
+		client: client,
+	}
+}
+
+func (c *HTTPCatalog) ProductPrice(ctx context.Context, productID uuid.UUID) (float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+"/products/"+productID.String(), nil)
+	if err != nil {
+		logger.Errorf("Error while creating catalog request %v", err)
+		return 0, errs.ErrGetProductPrice
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		logger.Errorf("Error while requesting product %v from catalog: %v", productID, err)
+		return 0, errs.ErrGetProductPrice
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return 0, errs.ErrProductNotFound
+	default:
+		logger.Errorf("Error while requesting product %v from catalog: unexpected status %d", productID, resp.StatusCode)
+		return 0, errs.ErrGetProductPrice
+	}
+
+	var p product
+	if err = json.NewDecoder(resp.Body).Decode(&p); err != nil {
+		logger.Errorf("Error while decoding product %v: %v", productID, err)
+		return 0, errs.ErrGetProductPrice
+	}
+	if p.Price <= 0 {
+		logger.Errorf("Error while requesting product %v from catalog: %v", productID, fmt.Errorf("invalid price %v", p.Price))
+		return 0, errs.ErrGetProductPrice
+	}
+	return p.Price, nil
+}
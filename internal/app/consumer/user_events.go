@@ -0,0 +1,164 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	logger "github.com/sirupsen/logrus"
+
+	"service-user/internal/app/errs"
+	"service-user/internal/app/events"
+	"service-user/internal/app/models"
+	"service-user/internal/app/service"
+	"service-user/internal/configs"
+)
+
+// Заголовки сообщения в очереди недоставленных: причина и откуда сообщение пришло
+const (
+	HeaderDeadLetterReason    = "dlq-reason"
+	HeaderDeadLetterTopic     = "dlq-original-topic"
+	HeaderDeadLetterPartition = "dlq-original-partition"
+	HeaderDeadLetterOffset    = "dlq-original-offset"
+)
+
+// MessageReader - чтение сообщений в группе потребителей, реализуется kafka.Reader
+type MessageReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// NewKafkaReader - чтение топика событий сервиса авторизации группой cfg.GroupID,
+// offset сохраняется только явным CommitMessages после обработки
+func NewKafkaReader(brokers []string, cfg *configs.UserEventsConfig) *kafka.Reader {
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     brokers,
+		GroupID:     cfg.GroupID,
+		Topic:       cfg.Topic,
+		StartOffset: kafka.FirstOffset,
+		MaxBytes:    1 << 20,
+	})
+}
+
+// UserEventsConsumer - обрабатывает события регистрации и удаления пользователей.
+// Сообщение, не прошедшее проверку, отправляется в очередь недоставленных, при остальных ошибках
+// обработка повторяется с задержкой. Offset сохраняется после обработки, поэтому сообщение может прийти
+// повторно, дубликаты отбрасываются по id события
+type UserEventsConsumer struct {
+	reader      MessageReader
+	deadLetters events.MessageWriter
+	service     service.UserEventsService
+	cfg         *configs.UserEventsConfig
+}
+
+func NewUserEventsConsumer(reader MessageReader, deadLetters events.MessageWriter, service service.UserEventsService, cfg *configs.UserEventsConfig) *UserEventsConsumer {
+	return &UserEventsConsumer{
+		reader:      reader,
+		deadLetters: deadLetters,
+		service:     service,
+		cfg:         cfg,
+	}
+}
+
+// Run читает и обрабатывает сообщения, пока не отменен ctx
+func (c *UserEventsConsumer) Run(ctx context.Context) {
+	logger.Infof("User events consumer started, topic %s, group %s", c.cfg.Topic, c.cfg.GroupID)
+	for {
+		msg, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			logger.Errorf("Error while fetching user event %v", err)
+			if !wait(ctx, c.cfg.RetryBase) {
+				break
+			}
+			continue
+		}
+
+		if !c.process(ctx, msg) {
+			break
+		}
+		if err = c.reader.CommitMessages(ctx, msg); err != nil {
+			logger.Errorf("Error while committing user event offset %d: %v", msg.Offset, err)
+		}
+	}
+	logger.Info("User events consumer stopped")
+}
+
+// process обрабатывает сообщение, пока это не удастся. Возвращает false, если ctx отменен
+func (c *UserEventsConsumer) process(ctx context.Context, msg kafka.Message) bool {
+	for attempt := 0; ; attempt++ {
+		err := c.handle(ctx, msg)
+		if errors.Is(err, errs.ErrInvalidEvent) {
+			logger.Warnf("Dead-lettering user event at offset %d: %v", msg.Offset, err)
+			err = c.deadLetter(ctx, msg, err)
+		}
+		if err == nil {
+			return true
+		}
+
+		delay := backoff(c.cfg.RetryBase, c.cfg.RetryMax, attempt)
+		logger.Errorf("Error while processing user event at offset %d, retry in %v: %v", msg.Offset, delay, err)
+		if !wait(ctx, delay) {
+			return false
+		}
+	}
+}
+
+func (c *UserEventsConsumer) handle(ctx context.Context, msg kafka.Message) error {
+	var event models.UserLifecycleEvent
+	if err := json.Unmarshal(msg.Value, &event); err != nil {
+		return fmt.Errorf("%w: %v", errs.ErrInvalidEvent, err)
+	}
+
+	// Изменения профиля в журнале связываются с событием, а не с запросом
+	ctx = models.WithRequestMeta(ctx, &models.RequestMeta{RequestID: event.ID.String()})
+	return c.service.HandleUserEvent(ctx, event)
+}
+
+// deadLetter - пересылка сообщения без изменений в очередь недоставленных с причиной в заголовке
+func (c *UserEventsConsumer) deadLetter(ctx context.Context, msg kafka.Message, reason error) error {
+	headers := append([]kafka.Header(nil), msg.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: HeaderDeadLetterReason, Value: []byte(reason.Error())},
+		kafka.Header{Key: HeaderDeadLetterTopic, Value: []byte(msg.Topic)},
+		kafka.Header{Key: HeaderDeadLetterPartition, Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: HeaderDeadLetterOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+	)
+	return c.deadLetters.WriteMessages(ctx, kafka.Message{
+		Topic:   c.cfg.DeadLetterTopic,
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	})
+}
+
+// backoff - задержка перед повтором: base, удваивается после каждой неудачи до max
+func backoff(base, max time.Duration, attempt int) time.Duration {
+	delay := base
+	for i := 0; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
+
+// wait ждет d или отмены ctx, возвращает false при отмене
+func wait(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,316 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+
+	"service-user/internal/app/errs"
+	"service-user/internal/app/models"
+	"service-user/internal/app/service"
+)
+
+type AdminHandler struct {
+	service service.Service
+}
+
+func NewAdminHandler(service service.Service) *AdminHandler {
+	return &AdminHandler{
+		service: service,
+	}
+}
+
+// RevokeToken - отзыв токена
+// @Summary Отозвать токен
+// @Description Отзывает токен по jti или все токены пользователя, выпущенные до текущего момента. Требует роль admin
+// @Tags Admin
+// @Accept  json
+// @Produce  json
+// @Param input body models.TokenRevocationInput true "jti с exp токена или user_id"
+// @Security CookieAuth
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse "Токен отозван"
+// @Failure 400 {object} middleware.ValidationErrorResponse "Неверный запрос"
+// @Failure 401 {object} middleware.ValidationErrorResponse "Не авторизован"
+// @Failure 403 {object} middleware.ValidationErrorResponse "Недостаточно прав"
+// @Failure 500 {object} middleware.ValidationErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/tokens/revoke [post]
+func (ah *AdminHandler) RevokeToken(c *gin.Context) {
+	var input models.TokenRevocationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		var validationErrs validator.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			c.Error(validationErrs)
+			return
+		}
+	}
+
+	if err := ah.service.RevokeToken(c, input); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Status: http.StatusOK,
+		Data:   "Token revoked successfully",
+	})
+}
+
+// AdminListProfiles - список профилей
+// @Summary Список профилей (admin)
+// @Description Возвращает страницу профилей с фильтрами. Следующая страница запрашивается с cursor = next_cursor. Требует роль admin
+// @Tags Admin
+// @Produce  json
+// @Param city query string false "Город"
+// @Param name query string false "Префикс имени или фамилии"
+// @Param created_from query string false "Создан не раньше (RFC3339)"
+// @Param created_to query string false "Создан раньше (RFC3339)"
+// @Param sort query string false "Поле сортировки" Enums(created_at, last_name)
+// @Param order query string false "Порядок сортировки" Enums(asc, desc)
+// @Param limit query int false "Размер страницы, до 100"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Security CookieAuth
+// @Security BearerAuth
+// @Success 200 {object} models.ProfileList "Страница профилей"
+// @Failure 400 {object} middleware.ValidationErrorResponse "Неверный запрос"
+// @Failure 401 {object} middleware.ValidationErrorResponse "Не авторизован"
+// @Failure 403 {object} middleware.ValidationErrorResponse "Недостаточно прав"
+// @Failure 500 {object} middleware.ValidationErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/profiles [get]
+func (ah *AdminHandler) AdminListProfiles(c *gin.Context) {
+	var filter models.ProfileListFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := filter.Validate(); err != nil {
+		var validationErrs validator.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			c.Error(validationErrs)
+			return
+		}
+	}
+
+	list, err := ah.service.ListProfiles(c, filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// AdminSearchProfiles - нечеткий поиск профилей
+// @Summary Поиск профилей (admin)
+// @Description Ищет профили по имени, фамилии и городу с учетом опечаток, лучшие совпадения первыми. Требует роль admin
+// @Tags Admin
+// @Produce  json
+// @Param q query string true "Строка поиска"
+// @Param limit query int false "Количество результатов, до 50"
+// @Security CookieAuth
+// @Security BearerAuth
+// @Success 200 {object} models.ProfileSearchResponse "Найденные профили"
+// @Failure 400 {object} middleware.ValidationErrorResponse "Неверный запрос"
+// @Failure 401 {object} middleware.ValidationErrorResponse "Не авторизован"
+// @Failure 403 {object} middleware.ValidationErrorResponse "Недостаточно прав"
+// @Failure 500 {object} middleware.ValidationErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/profiles/search [get]
+func (ah *AdminHandler) AdminSearchProfiles(c *gin.Context) {
+	var query models.ProfileSearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := query.Validate(); err != nil {
+		var validationErrs validator.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			c.Error(validationErrs)
+			return
+		}
+	}
+
+	results, err := ah.service.SearchProfiles(c, query.Q, query.Limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, models.ProfileSearchResponse{Items: results})
+}
+
+// AdminGetProfile - получение профиля любого пользователя
+// @Summary Получить профиль пользователя (admin)
+// @Description Возвращает профиль по id профиля или по user_id. Требует роль admin, действие записывается в журнал
+// @Tags Admin
+// @Produce  json
+// @Param id path string true "ID профиля"
+// @Security CookieAuth
+// @Security BearerAuth
+// @Success 200 {object} models.UserProfileOut "Информация о профиле"
+// @Failure 400 {object} middleware.ValidationErrorResponse "Неверный запрос"
+// @Failure 401 {object} middleware.ValidationErrorResponse "Не авторизован"
+// @Failure 403 {object} middleware.ValidationErrorResponse "Недостаточно прав"
+// @Failure 404 {object} middleware.ValidationErrorResponse "Профиль не найден"
+// @Failure 500 {object} middleware.ValidationErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/profiles/{id} [get]
+// @Router /admin/profiles/by-user/{id} [get]
+func (ah *AdminHandler) AdminGetProfile(c *gin.Context) {
+	ref, err := profileRefFromPath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	adminID := getUserIdFromContext(c)
+	profile, err := ah.service.AdminGetProfile(c, adminID, ref)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, profile)
+}
+
+// AdminUpdateProfile - обновление профиля любого пользователя
+// @Summary Обновить профиль пользователя (admin)
+// @Description Обновляет профиль по id профиля или по user_id. Требует роль admin, действие записывается в журнал
+// @Tags Admin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "ID профиля"
+// @Param input body models.UserProfileUpdate true "Новые данные профиля"
+// @Security CookieAuth
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse "Профиль успешно обновлен"
+// @Failure 400 {object} middleware.ValidationErrorResponse "Неверный запрос"
+// @Failure 401 {object} middleware.ValidationErrorResponse "Не авторизован"
+// @Failure 403 {object} middleware.ValidationErrorResponse "Недостаточно прав"
+// @Failure 404 {object} middleware.ValidationErrorResponse "Профиль не найден"
+// @Failure 500 {object} middleware.ValidationErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/profiles/{id} [patch]
+// @Router /admin/profiles/by-user/{id} [patch]
+func (ah *AdminHandler) AdminUpdateProfile(c *gin.Context) {
+	ref, err := profileRefFromPath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input models.UserProfileUpdate
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		var validationErrs validator.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			c.Error(validationErrs)
+			return
+		}
+	}
+
+	adminID := getUserIdFromContext(c)
+	err = ah.service.AdminUpdateProfile(c, adminID, ref, input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Status: http.StatusOK,
+		Data:   "Profile updated successfully",
+	})
+}
+
+// AdminDeleteProfile - удаление профиля любого пользователя
+// @Summary Удалить профиль пользователя (admin)
+// @Description Удаляет профиль по id профиля или по user_id. Требует роль admin, действие записывается в журнал
+// @Tags Admin
+// @Produce  json
+// @Param id path string true "ID профиля"
+// @Security CookieAuth
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse "Профиль успешно удален"
+// @Failure 400 {object} middleware.ValidationErrorResponse "Неверный запрос"
+// @Failure 401 {object} middleware.ValidationErrorResponse "Не авторизован"
+// @Failure 403 {object} middleware.ValidationErrorResponse "Недостаточно прав"
+// @Failure 404 {object} middleware.ValidationErrorResponse "Профиль не найден"
+// @Failure 500 {object} middleware.ValidationErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/profiles/{id} [delete]
+// @Router /admin/profiles/by-user/{id} [delete]
+func (ah *AdminHandler) AdminDeleteProfile(c *gin.Context) {
+	ref, err := profileRefFromPath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	adminID := getUserIdFromContext(c)
+	err = ah.service.AdminDeleteProfile(c, adminID, ref)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Status: http.StatusOK,
+		Data:   "Profile deleted successfully",
+	})
+}
+
+// AdminEraseProfile - стирание персональных данных любого пользователя
+// @Summary Стереть персональные данные пользователя (admin)
+// @Description Необратимо обезличивает профиль по id профиля или по user_id и удаляет карты. Требует роль admin, действие записывается в журнал
+// @Tags Admin
+// @Produce  json
+// @Param id path string true "ID профиля"
+// @Security CookieAuth
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse "Данные стерты"
+// @Failure 400 {object} middleware.ValidationErrorResponse "Неверный запрос"
+// @Failure 401 {object} middleware.ValidationErrorResponse "Не авторизован"
+// @Failure 403 {object} middleware.ValidationErrorResponse "Недостаточно прав"
+// @Failure 404 {object} middleware.ValidationErrorResponse "Профиль не найден"
+// @Failure 500 {object} middleware.ValidationErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/profiles/{id}/erase [post]
+// @Router /admin/profiles/by-user/{id}/erase [post]
+func (ah *AdminHandler) AdminEraseProfile(c *gin.Context) {
+	ref, err := profileRefFromPath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	adminID := getUserIdFromContext(c)
+	err = ah.service.AdminEraseProfile(c, adminID, ref)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Status: http.StatusOK,
+		Data:   "Personal data erased successfully",
+	})
+}
+
+// profileRefFromPath - ссылка на профиль из пути: /profiles/:id или /profiles/by-user/:id
+func profileRefFromPath(c *gin.Context) (models.ProfileRef, error) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return models.ProfileRef{}, errs.ErrInvalidProfileId
+	}
+	if strings.Contains(c.FullPath(), "/by-user/") {
+		return models.ProfileRef{UserID: id}, nil
+	}
+	return models.ProfileRef{ID: id}, nil
+}
@@ -0,0 +1,243 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+
+	"service-user/internal/app/errs"
+	"service-user/internal/app/models"
+	"service-user/internal/app/service"
+)
+
+type CardHandler struct {
+	service service.Service
+}
+
+func NewCardHandler(service service.Service) *CardHandler {
+	return &CardHandler{
+		service: service,
+	}
+}
+
+// CreateCard - добавление банковской карты
+// @Summary Добавить банковскую карту
+// @Description Привязывает новую банковскую карту к профилю пользователя
+// @Tags Cards
+// @Accept  json
+// @Produce  json
+// @Param input body models.UserBankCard true "Данные карты"
+// @Security CookieAuth
+// @Security BearerAuth
+// @Success 201 {object} models.BankCardIdResponse "Карта добавлена"
+// @Failure 400 {object} middleware.ValidationErrorResponse "Неверный запрос"
+// @Failure 401 {object} middleware.ValidationErrorResponse "Не авторизован"
+// @Failure 403 {object} middleware.ValidationErrorResponse "Недостаточно прав"
+// @Failure 404 {object} middleware.ValidationErrorResponse "Профиль не найден"
+// @Failure 409 {object} middleware.ValidationErrorResponse "Карта уже добавлена"
+// @Failure 500 {object} middleware.ValidationErrorResponse "Внутренняя ошибка сервера"
+// @Router /user-profile/cards [post]
+func (ch *CardHandler) CreateCard(c *gin.Context) {
+	var input models.UserBankCard
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		var validationErrs validator.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			c.Error(validationErrs)
+			return
+		}
+	}
+
+	userID := getUserIdFromContext(c)
+	cardID, err := ch.service.CreateCard(c, userID, input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.BankCardIdResponse{
+		ID: cardID,
+	})
+}
+
+// GetCards - список банковских карт
+// @Summary Получить банковские карты
+// @Description Возвращает все карты пользователя с маскированными номерами
+// @Tags Cards
+// @Produce  json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Success 200 {array} models.UserBankCardOut "Карты пользователя"
+// @Failure 401 {object} middleware.ValidationErrorResponse "Не авторизован"
+// @Failure 403 {object} middleware.ValidationErrorResponse "Недостаточно прав"
+// @Failure 404 {object} middleware.ValidationErrorResponse "Профиль не найден"
+// @Failure 500 {object} middleware.ValidationErrorResponse "Внутренняя ошибка сервера"
+// @Router /user-profile/cards [get]
+func (ch *CardHandler) GetCards(c *gin.Context) {
+	userID := getUserIdFromContext(c)
+	cards, err := ch.service.GetCards(c, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, cards)
+}
+
+// GetCard - банковская карта по id
+// @Summary Получить банковскую карту
+// @Description Возвращает карту пользователя с маскированным номером
+// @Tags Cards
+// @Produce  json
+// @Param id path string true "ID карты"
+// @Security CookieAuth
+// @Security BearerAuth
+// @Success 200 {object} models.UserBankCardOut "Карта пользователя"
+// @Failure 400 {object} middleware.ValidationErrorResponse "Неверный запрос"
+// @Failure 401 {object} middleware.ValidationErrorResponse "Не авторизован"
+// @Failure 403 {object} middleware.ValidationErrorResponse "Недостаточно прав"
+// @Failure 404 {object} middleware.ValidationErrorResponse "Карта не найдена"
+// @Failure 500 {object} middleware.ValidationErrorResponse "Внутренняя ошибка сервера"
+// @Router /user-profile/cards/{id} [get]
+func (ch *CardHandler) GetCard(c *gin.Context) {
+	cardID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(errs.ErrInvalidCardId)
+		return
+	}
+
+	userID := getUserIdFromContext(c)
+	card, err := ch.service.GetCard(c, userID, cardID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, card)
+}
+
+// UpdateCard - обновление банковской карты
+// @Summary Обновить банковскую карту
+// @Description Обновляет срок действия и имя держателя карты
+// @Tags Cards
+// @Accept  json
+// @Produce  json
+// @Param id path string true "ID карты"
+// @Param input body models.UserBankCardUpdate true "Новые данные карты"
+// @Security CookieAuth
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse "Карта обновлена"
+// @Failure 400 {object} middleware.ValidationErrorResponse "Неверный запрос"
+// @Failure 401 {object} middleware.ValidationErrorResponse "Не авторизован"
+// @Failure 403 {object} middleware.ValidationErrorResponse "Недостаточно прав"
+// @Failure 404 {object} middleware.ValidationErrorResponse "Карта не найдена"
+// @Failure 500 {object} middleware.ValidationErrorResponse "Внутренняя ошибка сервера"
+// @Router /user-profile/cards/{id} [patch]
+func (ch *CardHandler) UpdateCard(c *gin.Context) {
+	cardID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(errs.ErrInvalidCardId)
+		return
+	}
+
+	var input models.UserBankCardUpdate
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		var validationErrs validator.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			c.Error(validationErrs)
+			return
+		}
+	}
+
+	userID := getUserIdFromContext(c)
+	err = ch.service.UpdateCard(c, userID, cardID, input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Status: http.StatusOK,
+		Data:   "Bank card updated successfully",
+	})
+}
+
+// DeleteCard - удаление банковской карты
+// @Summary Удалить банковскую карту
+// @Description Отвязывает карту от профиля пользователя
+// @Tags Cards
+// @Produce  json
+// @Param id path string true "ID карты"
+// @Security CookieAuth
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse "Карта удалена"
+// @Failure 400 {object} middleware.ValidationErrorResponse "Неверный запрос"
+// @Failure 401 {object} middleware.ValidationErrorResponse "Не авторизован"
+// @Failure 403 {object} middleware.ValidationErrorResponse "Недостаточно прав"
+// @Failure 404 {object} middleware.ValidationErrorResponse "Карта не найдена"
+// @Failure 500 {object} middleware.ValidationErrorResponse "Внутренняя ошибка сервера"
+// @Router /user-profile/cards/{id} [delete]
+func (ch *CardHandler) DeleteCard(c *gin.Context) {
+	cardID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(errs.ErrInvalidCardId)
+		return
+	}
+
+	userID := getUserIdFromContext(c)
+	err = ch.service.DeleteCard(c, userID, cardID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Status: http.StatusOK,
+		Data:   "Bank card deleted successfully",
+	})
+}
+
+// SetDefaultCard - выбор карты по умолчанию
+// @Summary Сделать карту картой по умолчанию
+// @Description Делает карту картой по умолчанию, у профиля всегда одна такая карта
+// @Tags Cards
+// @Produce  json
+// @Param id path string true "ID карты"
+// @Security CookieAuth
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse "Карта по умолчанию изменена"
+// @Failure 400 {object} middleware.ValidationErrorResponse "Неверный запрос"
+// @Failure 401 {object} middleware.ValidationErrorResponse "Не авторизован"
+// @Failure 403 {object} middleware.ValidationErrorResponse "Недостаточно прав"
+// @Failure 404 {object} middleware.ValidationErrorResponse "Карта не найдена"
+// @Failure 500 {object} middleware.ValidationErrorResponse "Внутренняя ошибка сервера"
+// @Router /user-profile/cards/{id}/default [put]
+func (ch *CardHandler) SetDefaultCard(c *gin.Context) {
+	cardID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(errs.ErrInvalidCardId)
+		return
+	}
+
+	userID := getUserIdFromContext(c)
+	err = ch.service.SetDefaultCard(c, userID, cardID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Status: http.StatusOK,
+		Data:   "Default bank card updated successfully",
+	})
+}
@@ -0,0 +1,204 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+
+	"service-user/internal/app/errs"
+	"service-user/internal/app/models"
+	"service-user/internal/app/service"
+)
+
+type CartHandler struct {
+	service service.Service
+}
+
+func NewCartHandler(service service.Service) *CartHandler {
+	return &CartHandler{
+		service: service,
+	}
+}
+
+// GetCart - получение корзины пользователя
+// @Summary Получить корзину
+// @Description Возвращает корзину текущего пользователя вместе с товарами. Пока товар не добавлен, корзина пустая и без id
+// @Tags Cart
+// @Produce  json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Success 200 {object} models.CartOut "Корзина пользователя"
+// @Failure 401 {object} middleware.ValidationErrorResponse "Не авторизован"
+// @Failure 403 {object} middleware.ValidationErrorResponse "Недостаточно прав"
+// @Failure 404 {object} middleware.ValidationErrorResponse "Профиль не найден"
+// @Failure 500 {object} middleware.ValidationErrorResponse "Внутренняя ошибка сервера"
+// @Router /cart/ [get]
+func (ch *CartHandler) GetCart(c *gin.Context) {
+	userID := getUserIdFromContext(c)
+	cart, err := ch.service.GetCart(c, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, cart)
+}
+
+// AddCartItem - добавление товара в корзину
+// @Summary Добавить товар в корзину
+// @Description Добавляет товар в корзину по текущей цене из каталога товаров, повторное добавление увеличивает количество
+// @Tags Cart
+// @Accept  json
+// @Produce  json
+// @Param input body models.CartItemInput true "Товар"
+// @Security CookieAuth
+// @Security BearerAuth
+// @Success 201 {object} models.CartItemIdResponse "Товар добавлен"
+// @Failure 400 {object} middleware.ValidationErrorResponse "Неверный запрос"
+// @Failure 401 {object} middleware.ValidationErrorResponse "Не авторизован"
+// @Failure 403 {object} middleware.ValidationErrorResponse "Недостаточно прав"
+// @Failure 404 {object} middleware.ValidationErrorResponse "Профиль или товар не найден"
+// @Failure 500 {object} middleware.ValidationErrorResponse "Внутренняя ошибка сервера"
+// @Failure 502 {object} middleware.ValidationErrorResponse "Каталог товаров недоступен"
+// @Router /cart/items [post]
+func (ch *CartHandler) AddCartItem(c *gin.Context) {
+	var input models.CartItemInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		var validationErrs validator.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			c.Error(validationErrs)
+			return
+		}
+	}
+
+	userID := getUserIdFromContext(c)
+	itemID, err := ch.service.AddItem(c, userID, input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.CartItemIdResponse{
+		ID: itemID,
+	})
+}
+
+// UpdateCartItem - изменение количества товара в корзине
+// @Summary Изменить количество товара
+// @Description Устанавливает новое количество товара в корзине
+// @Tags Cart
+// @Accept  json
+// @Produce  json
+// @Param id path string true "ID позиции корзины"
+// @Param input body models.CartItemQuantityUpdate true "Новое количество"
+// @Security CookieAuth
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse "Количество обновлено"
+// @Failure 400 {object} middleware.ValidationErrorResponse "Неверный запрос"
+// @Failure 401 {object} middleware.ValidationErrorResponse "Не авторизован"
+// @Failure 403 {object} middleware.ValidationErrorResponse "Недостаточно прав"
+// @Failure 404 {object} middleware.ValidationErrorResponse "Товар не найден"
+// @Failure 500 {object} middleware.ValidationErrorResponse "Внутренняя ошибка сервера"
+// @Router /cart/items/{id} [patch]
+func (ch *CartHandler) UpdateCartItem(c *gin.Context) {
+	itemID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(errs.ErrInvalidCartItemId)
+		return
+	}
+
+	var input models.CartItemQuantityUpdate
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		var validationErrs validator.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			c.Error(validationErrs)
+			return
+		}
+	}
+
+	userID := getUserIdFromContext(c)
+	err = ch.service.UpdateItemQuantity(c, userID, itemID, input.Quantity)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Status: http.StatusOK,
+		Data:   "Cart item updated successfully",
+	})
+}
+
+// RemoveCartItem - удаление товара из корзины
+// @Summary Удалить товар из корзины
+// @Description Удаляет позицию из корзины пользователя
+// @Tags Cart
+// @Produce  json
+// @Param id path string true "ID позиции корзины"
+// @Security CookieAuth
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse "Товар удален"
+// @Failure 400 {object} middleware.ValidationErrorResponse "Неверный запрос"
+// @Failure 401 {object} middleware.ValidationErrorResponse "Не авторизован"
+// @Failure 403 {object} middleware.ValidationErrorResponse "Недостаточно прав"
+// @Failure 404 {object} middleware.ValidationErrorResponse "Товар не найден"
+// @Failure 500 {object} middleware.ValidationErrorResponse "Внутренняя ошибка сервера"
+// @Router /cart/items/{id} [delete]
+func (ch *CartHandler) RemoveCartItem(c *gin.Context) {
+	itemID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(errs.ErrInvalidCartItemId)
+		return
+	}
+
+	userID := getUserIdFromContext(c)
+	err = ch.service.RemoveItem(c, userID, itemID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Status: http.StatusOK,
+		Data:   "Cart item deleted successfully",
+	})
+}
+
+// ClearCart - очистка корзины
+// @Summary Очистить корзину
+// @Description Удаляет все товары из корзины пользователя
+// @Tags Cart
+// @Produce  json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse "Корзина очищена"
+// @Failure 401 {object} middleware.ValidationErrorResponse "Не авторизован"
+// @Failure 403 {object} middleware.ValidationErrorResponse "Недостаточно прав"
+// @Failure 404 {object} middleware.ValidationErrorResponse "Профиль не найден"
+// @Failure 500 {object} middleware.ValidationErrorResponse "Внутренняя ошибка сервера"
+// @Router /cart/ [delete]
+func (ch *CartHandler) ClearCart(c *gin.Context) {
+	userID := getUserIdFromContext(c)
+	err := ch.service.ClearCart(c, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Status: http.StatusOK,
+		Data:   "Cart cleared successfully",
+	})
+}
@@ -2,12 +2,14 @@ package http
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 
 	"service-user/docs"
 	"service-user/internal/app/delivery/middleware"
+	"service-user/internal/app/errs"
 	"service-user/internal/app/service"
 	"service-user/internal/app/utils"
 )
@@ -17,23 +19,70 @@ type UserProfileHandler interface {
 	GetProfile(c *gin.Context)
 	UpdateProfile(c *gin.Context)
 	DeleteProfile(c *gin.Context)
+	RestoreProfile(c *gin.Context)
+	ExportPersonalData(c *gin.Context)
+	EraseProfile(c *gin.Context)
+	GetProfileHistory(c *gin.Context)
+}
+
+type UserCartHandler interface {
+	GetCart(c *gin.Context)
+	AddCartItem(c *gin.Context)
+	UpdateCartItem(c *gin.Context)
+	RemoveCartItem(c *gin.Context)
+	ClearCart(c *gin.Context)
+}
+
+type UserCardHandler interface {
+	CreateCard(c *gin.Context)
+	GetCards(c *gin.Context)
+	GetCard(c *gin.Context)
+	UpdateCard(c *gin.Context)
+	DeleteCard(c *gin.Context)
+	SetDefaultCard(c *gin.Context)
+}
+
+type UserAdminHandler interface {
+	RevokeToken(c *gin.Context)
+	AdminListProfiles(c *gin.Context)
+	AdminSearchProfiles(c *gin.Context)
+	AdminGetProfile(c *gin.Context)
+	AdminUpdateProfile(c *gin.Context)
+	AdminDeleteProfile(c *gin.Context)
+	AdminEraseProfile(c *gin.Context)
 }
 
 type Handler struct {
-	auth *utils.JWTManager
+	auth         *utils.JWTManager
+	tokenSources []middleware.TokenSource
+	revocations  middleware.RevocationChecker
+	idempotency  middleware.IdempotencyStore
+	scopes       *middleware.ScopeChecker
 	UserProfileHandler
+	UserCartHandler
+	UserCardHandler
+	UserAdminHandler
 }
 
-func NewHandler(services *service.Service, auth *utils.JWTManager) *Handler {
+func NewHandler(services *service.Service, auth *utils.JWTManager, tokenSources []middleware.TokenSource, scopes *middleware.ScopeChecker) *Handler {
 	return &Handler{
 		auth:               auth,
+		tokenSources:       tokenSources,
+		scopes:             scopes,
+		revocations:        services.RevocationService,
+		idempotency:        services.IdempotencyService,
 		UserProfileHandler: NewProfileHandler(*services),
+		UserCartHandler:    NewCartHandler(*services),
+		UserCardHandler:    NewCardHandler(*services),
+		UserAdminHandler:   NewAdminHandler(*services),
 	}
 }
 
 func (h *Handler) InitRoutes() *gin.Engine {
 	router := gin.New()
-	router.Use(middleware.ErrorHandler())
+	// Контекст gin отдает значения из контекста запроса, так данные запроса доходят до репозиториев
+	router.ContextWithFallback = true
+	router.Use(middleware.ErrorHandler(), middleware.RequestMeta())
 
 	docs.SwaggerInfo.BasePath = "/api/v1"
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -41,14 +90,71 @@ func (h *Handler) InitRoutes() *gin.Engine {
 	apiV1 := router.Group("/api/v1")
 	{
 		profile := apiV1.Group("/user-profile")
-		profile.Use(middleware.AuthMiddleware(h.auth))
+		profile.Use(middleware.AuthMiddleware(h.auth, h.tokenSources, h.revocations), middleware.Idempotency(h.idempotency))
+		{
+			profile.POST("/", h.scopes.Require("profile:write"), h.CreateProfile)
+			profile.GET("/", h.scopes.Require("profile:read"), h.GetProfile)
+			profile.PATCH("/", h.scopes.Require("profile:write"), h.UpdateProfile)
+			profile.DELETE("/", h.scopes.Require("profile:write"), h.DeleteProfile)
+			profile.POST("/restore", h.scopes.Require("profile:write"), h.RestoreProfile)
+			profile.GET("/export", h.scopes.Require("profile:read"), h.ExportPersonalData)
+			profile.GET("/history", h.scopes.Require("profile:read"), h.GetProfileHistory)
+			profile.POST("/erase", h.scopes.Require("profile:write"), h.EraseProfile)
+
+			profile.POST("/cards", h.scopes.Require("cards:write"), h.CreateCard)
+			profile.GET("/cards", h.scopes.Require("cards:read"), h.GetCards)
+			profile.GET("/cards/:id", h.scopes.Require("cards:read"), h.GetCard)
+			profile.PATCH("/cards/:id", h.scopes.Require("cards:write"), h.UpdateCard)
+			profile.DELETE("/cards/:id", h.scopes.Require("cards:write"), h.DeleteCard)
+			profile.PUT("/cards/:id/default", h.scopes.Require("cards:write"), h.SetDefaultCard)
+		}
+
+		cart := apiV1.Group("/cart")
+		cart.Use(middleware.AuthMiddleware(h.auth, h.tokenSources, h.revocations), middleware.Idempotency(h.idempotency))
 		{
-			profile.POST("/", h.CreateProfile)
-			profile.GET("/", h.GetProfile)
-			profile.PATCH("/", h.UpdateProfile)
-			profile.DELETE("/", h.DeleteProfile)
+			cart.GET("/", h.scopes.Require("cart:read"), h.GetCart)
+			cart.DELETE("/", h.scopes.Require("cart:write"), h.ClearCart)
+			cart.POST("/items", h.scopes.Require("cart:write"), h.AddCartItem)
+			cart.PATCH("/items/:id", h.scopes.Require("cart:write"), h.UpdateCartItem)
+			cart.DELETE("/items/:id", h.scopes.Require("cart:write"), h.RemoveCartItem)
+		}
+
+		admin := apiV1.Group("/admin")
+		admin.Use(middleware.AuthMiddleware(h.auth, h.tokenSources, h.revocations), middleware.RequireRole("admin"),
+			middleware.Idempotency(h.idempotency))
+		{
+			admin.POST("/tokens/revoke", h.RevokeToken)
+
+			admin.GET("/profiles", h.AdminListProfiles)
+			admin.GET("/profiles/search", h.AdminSearchProfiles)
+			admin.GET("/profiles/:id", h.AdminGetProfile)
+			admin.PATCH("/profiles/:id", h.AdminUpdateProfile)
+			admin.DELETE("/profiles/:id", h.AdminDeleteProfile)
+			admin.GET("/profiles/by-user/:id", h.AdminGetProfile)
+			admin.PATCH("/profiles/by-user/:id", h.AdminUpdateProfile)
+			admin.DELETE("/profiles/by-user/:id", h.AdminDeleteProfile)
+			admin.POST("/profiles/:id/erase", h.AdminEraseProfile)
+			admin.POST("/profiles/by-user/:id/erase", h.AdminEraseProfile)
 		}
 	}
 
 	return router
 }
+
+// getUserIdFromContext - user_id, положенный в контекст AuthMiddleware
+func getUserIdFromContext(c *gin.Context) uuid.UUID {
+	// Получаем user_id из контекста
+	userIDRaw, exists := c.Get("user_id")
+	if !exists {
+		c.Error(errs.ErrUnauthorized)
+		return uuid.UUID{}
+	}
+
+	// Приводим userID к строке и парсим UUID
+	userID, ok := userIDRaw.(uuid.UUID)
+	if !ok {
+		c.Error(errs.ErrInvalidUserId)
+		return uuid.UUID{}
+	}
+	return userID
+}
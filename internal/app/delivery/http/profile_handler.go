@@ -1,12 +1,20 @@
 package http
 
 import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
 	"errors"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	logger "github.com/sirupsen/logrus"
 
 	"service-user/internal/app/errs"
 	"service-user/internal/app/models"
@@ -35,9 +43,11 @@ func NewProfileHandler(service service.Service) *ProfileHandler {
 // @Produce  json
 // @Param input body models.UserProfileInput true "Информация о профиле"
 // @Security CookieAuth
+// @Security BearerAuth
 // @Success 201 {object} models.ProfileIdResponse "Успешно создан профиль"
 // @Failure 400 {object} middleware.ValidationErrorResponse "Неверный запрос"
 // @Failure 401 {object} middleware.ValidationErrorResponse "Не авторизован"
+// @Failure 403 {object} middleware.ValidationErrorResponse "Недостаточно прав"
 // @Failure 500 {object} middleware.ValidationErrorResponse "Внутренняя ошибка сервера"
 // @Router /user-profile/ [post]
 func (ph *ProfileHandler) CreateProfile(c *gin.Context) {
@@ -85,8 +95,16 @@ func (ph *ProfileHandler) CreateProfile(c *gin.Context) {
 // @Tags Profile
 // @Produce  json
 // @Security CookieAuth
+// @Security BearerAuth
+// @Param If-None-Match header string false "ETag сохраненного профиля"
+// @Param If-Modified-Since header string false "Last-Modified сохраненного профиля"
 // @Success 200 {object} models.UserProfileOut "Информация о профиле"
+// @Header 200 {string} ETag "Версия профиля для If-Match и If-None-Match"
+// @Header 200 {string} Last-Modified "Время последнего изменения профиля"
+// @Header 200 {string} Cache-Control "private, no-cache"
+// @Success 304 "Профиль не изменился"
 // @Failure 401 {object} middleware.ValidationErrorResponse "Не авторизован"
+// @Failure 403 {object} middleware.ValidationErrorResponse "Недостаточно прав"
 // @Failure 404 {object} middleware.ValidationErrorResponse "Профиль не найден"
 // @Failure 500 {object} middleware.ValidationErrorResponse "Внутренняя ошибка сервера"
 // @Router /user-profile/ [get]
@@ -97,24 +115,92 @@ func (ph *ProfileHandler) GetProfile(c *gin.Context) {
 		c.Error(err)
 		return
 	}
+	// Профиль можно хранить только в кэше клиента и перед использованием проверять условным запросом
+	current := models.ProfileVersion{ProfileID: userProfile.ID, Version: userProfile.Version}
+	c.Header("ETag", profileETag(current))
+	c.Header("Last-Modified", userProfile.UpdatedAt.UTC().Format(http.TimeFormat))
+	c.Header("Cache-Control", "private, no-cache")
+	c.Header("Vary", "Authorization, Cookie")
+	if notModified(c, current, userProfile.UpdatedAt) {
+		c.Status(http.StatusNotModified)
+		return
+	}
 	c.JSON(http.StatusOK, userProfile)
 }
 
 func (ph *ProfileHandler) GetUserIdFromContext(c *gin.Context) uuid.UUID {
-	// Получаем user_id из контекста
-	userIDRaw, exists := c.Get("user_id")
-	if !exists {
-		c.Error(errs.ErrUnauthorized)
-		return uuid.UUID{}
+	return getUserIdFromContext(c)
+}
+
+// profileETag - строгий ETag "<id профиля>-<версия>". Id нужен, чтобы ETag удаленного профиля
+// не совпал с ETag профиля, созданного заново и снова начавшего с первой версии
+func profileETag(version models.ProfileVersion) string {
+	return `"` + version.ProfileID.String() + "-" + strconv.FormatInt(version.Version, 10) + `"`
+}
+
+// parseProfileETag - id и версия профиля из строгого ETag, выданного profileETag
+func parseProfileETag(etag string) (models.ProfileVersion, bool) {
+	if len(etag) < 2 || etag[0] != '"' || etag[len(etag)-1] != '"' {
+		return models.ProfileVersion{}, false
+	}
+	value := etag[1 : len(etag)-1]
+	// id профиля сам содержит дефисы, версия идет после последнего
+	i := strings.LastIndexByte(value, '-')
+	if i < 0 {
+		return models.ProfileVersion{}, false
+	}
+	profileID, err := uuid.Parse(value[:i])
+	if err != nil {
+		return models.ProfileVersion{}, false
+	}
+	version, err := strconv.ParseInt(value[i+1:], 10, 64)
+	if err != nil || version <= 0 {
+		return models.ProfileVersion{}, false
+	}
+	return models.ProfileVersion{ProfileID: profileID, Version: version}, true
+}
+
+// notModified - проверка условного GET для профиля current. If-None-Match сравнивается слабым сравнением
+// по id и версии профиля и имеет приоритет, If-Modified-Since учитывается только без него
+func notModified(c *gin.Context, current models.ProfileVersion, modified time.Time) bool {
+	if ifNoneMatch := c.GetHeader("If-None-Match"); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" {
+				return true
+			}
+			// ETag удаленного профиля той же версии не подходит к профилю, созданному заново
+			if version, ok := parseProfileETag(candidate); ok && version == current {
+				return true
+			}
+		}
+		return false
+	}
+
+	since, err := http.ParseTime(c.GetHeader("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	// Last-Modified передается с точностью до секунды
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// ifMatchVersion - id и версия профиля из If-Match. Значение, не являющееся ETag профиля,
+// не совпадает ни с одной версией, поэтому сразу отклоняется как конфликт версий
+func ifMatchVersion(c *gin.Context) (models.ProfileVersion, error) {
+	value := strings.TrimSpace(c.GetHeader("If-Match"))
+	switch value {
+	case "":
+		return models.ProfileVersion{Version: models.NoVersion}, nil
+	case "*":
+		return models.ProfileVersion{Version: models.AnyVersion}, nil
 	}
 
-	// Приводим userID к строке и парсим UUID
-	userID, ok := userIDRaw.(uuid.UUID)
+	version, ok := parseProfileETag(value)
 	if !ok {
-		c.Error(errs.ErrInvalidUserId)
-		return uuid.UUID{}
+		return models.ProfileVersion{}, errs.ErrVersionConflict
 	}
-	return userID
+	return version, nil
 }
 
 // @securityDefinitions.cookie CookieAuth
@@ -128,10 +214,16 @@ func (ph *ProfileHandler) GetUserIdFromContext(c *gin.Context) uuid.UUID {
 // @Accept  json
 // @Produce  json
 // @Security CookieAuth
+// @Security BearerAuth
 // @Param input body models.UserProfileUpdate true "Новые данные профиля"
+// @Param If-Match header string false "ETag профиля из GET, обязателен при profiles.require_if_match"
 // @Success 200 {object} models.SuccessResponse "Профиль успешно обновлен"
+// @Header 200 {string} ETag "Новая версия профиля"
 // @Failure 400 {object} middleware.ValidationErrorResponse "Неверный запрос"
 // @Failure 401 {object} middleware.ValidationErrorResponse "Не авторизован"
+// @Failure 403 {object} middleware.ValidationErrorResponse "Недостаточно прав"
+// @Failure 412 {object} middleware.ValidationErrorResponse "Профиль изменен после получения ETag"
+// @Failure 428 {object} middleware.ValidationErrorResponse "Не передан If-Match"
 // @Failure 500 {object} middleware.ValidationErrorResponse "Внутренняя ошибка сервера"
 // @Router /user-profile/ [patch]
 func (ph *ProfileHandler) UpdateProfile(c *gin.Context) {
@@ -149,15 +241,23 @@ func (ph *ProfileHandler) UpdateProfile(c *gin.Context) {
 		}
 	}
 
+	ifMatch, err := ifMatchVersion(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
 	userID := ph.GetUserIdFromContext(c)
 	input.UserID = userID
+	input.IfMatch = ifMatch
 
-	err := ph.service.UpdateProfile(c, input)
+	version, err := ph.service.UpdateProfile(c, input)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
+	c.Header("ETag", profileETag(version))
 	response := models.SuccessResponse{
 		Status: http.StatusOK,
 		Data:   "Profile updated successfully",
@@ -172,17 +272,28 @@ func (ph *ProfileHandler) UpdateProfile(c *gin.Context) {
 
 // DeleteProfile - удаление профиля пользователя
 // @Summary Удалить профиль пользователя
-// @Description Удаляет профиль пользователя по его ID
+// @Description Удаляет профиль пользователя. Профиль можно восстановить в течение срока profiles.delete_grace_period, после него профиль удаляется вместе с корзиной и картами
 // @Tags Profile
+// @Param If-Match header string false "ETag профиля из GET, обязателен при profiles.require_if_match"
 // @Security CookieAuth
+// @Security BearerAuth
 // @Success 204 {object} models.SuccessResponse"Профиль успешно удален"
 // @Failure 401 {object} middleware.ValidationErrorResponse "Не авторизован"
+// @Failure 403 {object} middleware.ValidationErrorResponse "Недостаточно прав"
 // @Failure 404 {object} middleware.ValidationErrorResponse "Ошибка при удалении пользователя"
+// @Failure 412 {object} middleware.ValidationErrorResponse "Профиль изменен после получения ETag"
+// @Failure 428 {object} middleware.ValidationErrorResponse "Не передан If-Match"
 // @Failure 500 {object} middleware.ValidationErrorResponse "Внутренняя ошибка сервера"
 // @Router /user-profile/ [delete]
 func (ph *ProfileHandler) DeleteProfile(c *gin.Context) {
+	ifMatch, err := ifMatchVersion(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
 	userID := ph.GetUserIdFromContext(c)
-	err := ph.service.DeleteProfile(c, userID)
+	err = ph.service.DeleteProfile(c, userID, ifMatch)
 	if err != nil {
 		c.Error(err)
 		return
@@ -193,3 +304,171 @@ func (ph *ProfileHandler) DeleteProfile(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, response)
 }
+
+// RestoreProfile - восстановление удаленного профиля
+// @Summary Восстановить профиль пользователя
+// @Description Восстанавливает удаленный профиль, если срок восстановления еще не истек
+// @Tags Profile
+// @Produce  json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse "Профиль восстановлен"
+// @Failure 401 {object} middleware.ValidationErrorResponse "Не авторизован"
+// @Failure 403 {object} middleware.ValidationErrorResponse "Недостаточно прав"
+// @Failure 404 {object} middleware.ValidationErrorResponse "Профиль не найден"
+// @Failure 409 {object} middleware.ValidationErrorResponse "Профиль не удален"
+// @Failure 410 {object} middleware.ValidationErrorResponse "Срок восстановления истек"
+// @Failure 500 {object} middleware.ValidationErrorResponse "Внутренняя ошибка сервера"
+// @Router /user-profile/restore [post]
+func (ph *ProfileHandler) RestoreProfile(c *gin.Context) {
+	userID := ph.GetUserIdFromContext(c)
+	err := ph.service.RestoreProfile(c, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Status: http.StatusOK,
+		Data:   "Profile restored successfully",
+	})
+}
+
+// EraseProfile - стирание персональных данных пользователя
+// @Summary Стереть персональные данные
+// @Description Необратимо заменяет имя, фамилию и город псевдонимами и удаляет карты. Корзина остается за обезличенным профилем
+// @Tags Profile
+// @Produce  json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse "Данные стерты"
+// @Failure 401 {object} middleware.ValidationErrorResponse "Не авторизован"
+// @Failure 403 {object} middleware.ValidationErrorResponse "Недостаточно прав"
+// @Failure 404 {object} middleware.ValidationErrorResponse "Профиль не найден"
+// @Failure 500 {object} middleware.ValidationErrorResponse "Внутренняя ошибка сервера"
+// @Router /user-profile/erase [post]
+func (ph *ProfileHandler) EraseProfile(c *gin.Context) {
+	userID := ph.GetUserIdFromContext(c)
+	err := ph.service.EraseProfile(c, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Status: http.StatusOK,
+		Data:   "Personal data erased successfully",
+	})
+}
+
+// GetProfileHistory - журнал изменений профиля пользователя
+// @Summary Получить историю изменений профиля
+// @Description Возвращает изменения профиля от новых к старым: кто и с какого адреса менял профиль, старые и новые значения полей
+// @Tags Profile
+// @Produce  json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Success 200 {object} models.ProfileHistoryResponse "История изменений"
+// @Failure 401 {object} middleware.ValidationErrorResponse "Не авторизован"
+// @Failure 403 {object} middleware.ValidationErrorResponse "Недостаточно прав"
+// @Failure 500 {object} middleware.ValidationErrorResponse "Внутренняя ошибка сервера"
+// @Router /user-profile/history [get]
+func (ph *ProfileHandler) GetProfileHistory(c *gin.Context) {
+	userID := ph.GetUserIdFromContext(c)
+	history, err := ph.service.GetProfileHistory(c, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ProfileHistoryResponse{Items: history})
+}
+
+// ExportPersonalData - выгрузка персональных данных пользователя
+// @Summary Выгрузить персональные данные
+// @Description Возвращает все данные пользователя: профиль, корзину, карты (номера маскированы) и историю действий с профилем. format=zip отдает архив с файлом на каждый раздел
+// @Tags Profile
+// @Produce  json
+// @Produce  application/zip
+// @Param format query string false "Формат выгрузки" Enums(json, zip)
+// @Security CookieAuth
+// @Security BearerAuth
+// @Success 200 {object} models.PersonalDataExport "Персональные данные"
+// @Failure 400 {object} middleware.ValidationErrorResponse "Неверный запрос"
+// @Failure 401 {object} middleware.ValidationErrorResponse "Не авторизован"
+// @Failure 403 {object} middleware.ValidationErrorResponse "Недостаточно прав"
+// @Failure 404 {object} middleware.ValidationErrorResponse "Профиль не найден"
+// @Failure 500 {object} middleware.ValidationErrorResponse "Внутренняя ошибка сервера"
+// @Router /user-profile/export [get]
+func (ph *ProfileHandler) ExportPersonalData(c *gin.Context) {
+	var query models.PersonalDataExportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := query.Validate(); err != nil {
+		var validationErrs validator.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			c.Error(validationErrs)
+			return
+		}
+	}
+
+	userID := ph.GetUserIdFromContext(c)
+	export, err := ph.service.ExportPersonalData(c, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	filename := "personal-data-" + export.GeneratedAt.Format("2006-01-02")
+	if query.Format != models.ExportFormatZIP {
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`.json"`)
+		c.JSON(http.StatusOK, export)
+		return
+	}
+
+	// Архив собираем целиком в памяти, чтобы ошибка не оборвала уже начатый ответ
+	archive, err := exportArchive(export)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`.zip"`)
+	c.Data(http.StatusOK, "application/zip", archive)
+}
+
+// exportArchive - ZIP архив с отдельным JSON файлом на каждый раздел выгрузки
+func exportArchive(export models.PersonalDataExport) ([]byte, error) {
+	sections := export.Sections()
+	names := make([]string, 0, len(sections))
+	for name := range sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, name := range names {
+		data, err := json.MarshalIndent(sections[name], "", "  ")
+		if err != nil {
+			logger.Errorf("Error while encoding export section %s: %v", name, err)
+			return nil, errs.ErrExportPersonalData
+		}
+
+		file, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: export.GeneratedAt})
+		if err == nil {
+			_, err = file.Write(data)
+		}
+		if err != nil {
+			logger.Errorf("Error while writing export archive %v", err)
+			return nil, errs.ErrExportPersonalData
+		}
+	}
+	if err := archive.Close(); err != nil {
+		logger.Errorf("Error while writing export archive %v", err)
+		return nil, errs.ErrExportPersonalData
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,148 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"service-user/internal/app/errs"
+	"service-user/internal/app/models"
+)
+
+// testContext - контекст gin для GET запроса с заголовками headers (имя, значение, ...)
+func testContext(headers ...string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/user-profile/", nil)
+	for i := 0; i+1 < len(headers); i += 2 {
+		c.Request.Header.Set(headers[i], headers[i+1])
+	}
+	return c
+}
+
+var testProfileID = uuid.MustParse("5f0c6a34-2b1e-4c55-9a3d-7c1b8e9f0a12")
+
+func TestProfileETag(t *testing.T) {
+	want := `"5f0c6a34-2b1e-4c55-9a3d-7c1b8e9f0a12-7"`
+	if got := profileETag(models.ProfileVersion{ProfileID: testProfileID, Version: 7}); got != want {
+		t.Fatalf("profileETag = %s, want %s", got, want)
+	}
+	// ETag, выданный в ответе, принимается в If-Match
+	issued := models.ProfileVersion{ProfileID: testProfileID, Version: 42}
+	c := testContext("If-Match", profileETag(issued))
+	if version, err := ifMatchVersion(c); err != nil || version != issued {
+		t.Fatalf("ifMatchVersion(profileETag(%v)) = %v, %v", issued, version, err)
+	}
+}
+
+func TestIfMatchVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		version models.ProfileVersion
+	}{
+		{name: "no header", header: "", version: models.ProfileVersion{Version: models.NoVersion}},
+		{name: "any", header: "*", version: models.ProfileVersion{Version: models.AnyVersion}},
+		{
+			name:    "version",
+			header:  `"5f0c6a34-2b1e-4c55-9a3d-7c1b8e9f0a12-3"`,
+			version: models.ProfileVersion{ProfileID: testProfileID, Version: 3},
+		},
+		{
+			name:    "spaces",
+			header:  ` "5f0c6a34-2b1e-4c55-9a3d-7c1b8e9f0a12-3" `,
+			version: models.ProfileVersion{ProfileID: testProfileID, Version: 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, err := ifMatchVersion(testContext("If-Match", tt.header))
+			if err != nil {
+				t.Fatalf("ifMatchVersion(%q): %v", tt.header, err)
+			}
+			if version != tt.version {
+				t.Fatalf("ifMatchVersion(%q) = %v, want %v", tt.header, version, tt.version)
+			}
+		})
+	}
+}
+
+func TestIfMatchVersionRejectsForeignETags(t *testing.T) {
+	// Такие значения не совпадают ни с одной версией профиля, в том числе ETag без id профиля
+	for _, header := range []string{
+		`3`, `"3"`, `W/"5f0c6a34-2b1e-4c55-9a3d-7c1b8e9f0a12-3"`, `"abc"`, `"abc-3"`,
+		`"5f0c6a34-2b1e-4c55-9a3d-7c1b8e9f0a12-0"`, `"5f0c6a34-2b1e-4c55-9a3d-7c1b8e9f0a12-x"`,
+		`"5f0c6a34-2b1e-4c55-9a3d-7c1b8e9f0a12"`, `""`, `"`,
+		`"5f0c6a34-2b1e-4c55-9a3d-7c1b8e9f0a12-3", "5f0c6a34-2b1e-4c55-9a3d-7c1b8e9f0a12-4"`,
+	} {
+		t.Run(header, func(t *testing.T) {
+			if _, err := ifMatchVersion(testContext("If-Match", header)); !errors.Is(err, errs.ErrVersionConflict) {
+				t.Fatalf("ifMatchVersion(%q): err = %v, want ErrVersionConflict", header, err)
+			}
+		})
+	}
+}
+
+func TestIfMatchVersionRejectsRecreatedProfile(t *testing.T) {
+	// Профиль удален и создан заново: новый профиль снова первой версии, но ETag прежнего к нему не подходит
+	recreatedID := uuid.MustParse("0b7e2f4d-91c3-4d8a-8f26-3e5a7c9d1b40")
+	c := testContext("If-Match", profileETag(models.ProfileVersion{ProfileID: testProfileID, Version: 1}))
+	ifMatch, err := ifMatchVersion(c)
+	if err != nil {
+		t.Fatalf("ifMatchVersion: %v", err)
+	}
+	if ifMatch.Matches(recreatedID, 1) {
+		t.Fatal("ETag of the deleted profile matches the re-created profile")
+	}
+	if !ifMatch.Matches(testProfileID, 1) {
+		t.Fatal("ETag does not match the profile it was issued for")
+	}
+	if ifMatch.Matches(testProfileID, 2) {
+		t.Fatal("ETag matches a newer version of the profile")
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	current := models.ProfileVersion{ProfileID: testProfileID, Version: 5}
+	modified := time.Date(2024, 5, 10, 12, 0, 0, 500_000_000, time.UTC)
+	lastModified := modified.Format(http.TimeFormat)
+
+	tests := []struct {
+		name    string
+		headers []string
+		want    bool
+	}{
+		{name: "no headers", want: false},
+		{name: "same etag", headers: []string{"If-None-Match", `"5f0c6a34-2b1e-4c55-9a3d-7c1b8e9f0a12-5"`}, want: true},
+		{name: "weak etag", headers: []string{"If-None-Match", `W/"5f0c6a34-2b1e-4c55-9a3d-7c1b8e9f0a12-5"`}, want: true},
+		{name: "etag in list", headers: []string{"If-None-Match", `"5f0c6a34-2b1e-4c55-9a3d-7c1b8e9f0a12-4",  "5f0c6a34-2b1e-4c55-9a3d-7c1b8e9f0a12-5"`}, want: true},
+		{name: "any", headers: []string{"If-None-Match", "*"}, want: true},
+		{name: "other etag", headers: []string{"If-None-Match", `"5f0c6a34-2b1e-4c55-9a3d-7c1b8e9f0a12-4"`}, want: false},
+		{name: "unquoted etag", headers: []string{"If-None-Match", `5f0c6a34-2b1e-4c55-9a3d-7c1b8e9f0a12-5`}, want: false},
+		{name: "etag without profile id", headers: []string{"If-None-Match", `"5"`}, want: false},
+		{name: "upper case profile id", headers: []string{"If-None-Match", `"5F0C6A34-2B1E-4C55-9A3D-7C1B8E9F0A12-5"`}, want: true},
+		// Профиль удален и создан заново: та же версия, но другой профиль
+		{name: "re-created profile", headers: []string{"If-None-Match", `"0b7e2f4d-91c3-4d8a-8f26-3e5a7c9d1b40-5"`}, want: false},
+		// Last-Modified отдается без долей секунды, поэтому изменение в ту же секунду не считается новым
+		{name: "since last modified", headers: []string{"If-Modified-Since", lastModified}, want: true},
+		{name: "since later", headers: []string{"If-Modified-Since", modified.Add(time.Hour).Format(http.TimeFormat)}, want: true},
+		{name: "since earlier", headers: []string{"If-Modified-Since", modified.Add(-time.Second).Format(http.TimeFormat)}, want: false},
+		{name: "bad date", headers: []string{"If-Modified-Since", "yesterday"}, want: false},
+		{
+			name:    "etag wins over date",
+			headers: []string{"If-None-Match", `"5f0c6a34-2b1e-4c55-9a3d-7c1b8e9f0a12-4"`, "If-Modified-Since", lastModified},
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := notModified(testContext(tt.headers...), current, modified); got != tt.want {
+				t.Fatalf("notModified(%q) = %v, want %v", tt.headers, got, tt.want)
+			}
+		})
+	}
+}
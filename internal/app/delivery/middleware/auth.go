@@ -1,7 +1,6 @@
 package middleware
 
 import (
-	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -9,16 +8,22 @@ import (
 	"github.com/google/uuid"
 
 	"service-user/internal/app/errs"
+	"service-user/internal/app/models"
 	"service-user/internal/app/utils"
 )
 
-// AuthMiddleware проверяет JWT токен
-func AuthMiddleware(jwtManager *utils.JWTManager) gin.HandlerFunc {
+// RevocationChecker - список отзыва токенов
+type RevocationChecker interface {
+	IsRevoked(claims models.AccessClaims) bool
+}
+
+// AuthMiddleware проверяет JWT токен, найденный в одном из источников, и что он не отозван
+func AuthMiddleware(jwtManager *utils.JWTManager, sources []TokenSource, revocations RevocationChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Извлекаем токен из cookies
-		tokenString, err := extractTokenFromCookie(c)
+		// Ищем токен в источниках по порядку
+		tokenString, err := extractToken(c, sources)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Error(err)
 			c.Abort()
 			return
 		}
@@ -26,46 +31,81 @@ func AuthMiddleware(jwtManager *utils.JWTManager) gin.HandlerFunc {
 		// Парсим и валидируем токен
 		claims, err := jwtManager.DecodeJWT(tokenString)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			c.Error(err)
 			c.Abort()
 			return
 		}
 
-		// Извлекаем user_id
-		userID, err := extractUserID(claims)
+		// Извлекаем user_id, роли и scope
+		accessClaims, err := extractClaims(claims)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token payload"})
+			c.Error(errs.ErrTokenInvalid)
 			c.Abort()
 			return
 		}
 
-		// Передаем user_id в контекст запроса
-		c.Set("user_id", userID)
-		c.Next()
-	}
-}
+		// Отозванный токен отклоняем, даже если он еще не истек
+		if revocations.IsRevoked(accessClaims) {
+			c.Error(errs.ErrTokenRevoked)
+			c.Abort()
+			return
+		}
 
-// extractTokenFromCookie извлекает access_token из cookie
-func extractTokenFromCookie(c *gin.Context) (string, error) {
-	cookie, err := c.Cookie("access_token")
-	if err != nil {
-		return "", err
+		// Передаем user_id и claims в контекст запроса
+		models.RequestMetaFromContext(c.Request.Context()).ActorID = accessClaims.UserID
+		c.Set("user_id", accessClaims.UserID)
+		c.Set(claimsKey, accessClaims)
+		c.Next()
 	}
-	return strings.TrimSpace(cookie), nil
 }
 
-// extractUserID извлекает user_id из claims
-func extractUserID(claims jwt.Claims) (uuid.UUID, error) {
+// extractClaims извлекает user_id, сессию, роли и scope из claims
+func extractClaims(claims jwt.Claims) (models.AccessClaims, error) {
 	mapClaims, ok := claims.(jwt.MapClaims)
 	if !ok {
-		return uuid.Nil, errs.ErrTokenInvalid
+		return models.AccessClaims{}, errs.ErrTokenInvalid
 	}
 
 	sub, ok := mapClaims["sub"].(string)
-
 	if !ok {
-		return uuid.Nil, errs.ErrTokenInvalid
+		return models.AccessClaims{}, errs.ErrTokenInvalid
+	}
+	userID, err := uuid.Parse(sub)
+	if err != nil {
+		return models.AccessClaims{}, err
+	}
+
+	accessClaims := models.AccessClaims{UserID: userID}
+	accessClaims.TokenID, _ = mapClaims["jti"].(string)
+	accessClaims.SessionID, _ = mapClaims["sid"].(string)
+	if iat, err := mapClaims.GetIssuedAt(); err == nil && iat != nil {
+		accessClaims.IssuedAt = iat.Time
 	}
+	accessClaims.Roles = stringList(mapClaims["roles"])
 
-	return uuid.Parse(sub)
+	// scope по RFC 8693 - строка через пробел, некоторые серверы выдают массив scp
+	if scope, ok := mapClaims["scope"].(string); ok {
+		accessClaims.Scopes = strings.Fields(scope)
+	} else {
+		accessClaims.Scopes = stringList(mapClaims["scp"])
+	}
+	return accessClaims, nil
+}
+
+// stringList - значение claim, которое может быть строкой или массивом строк
+func stringList(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	default:
+		return nil
+	}
 }
@@ -37,9 +37,45 @@ func ErrorHandler() gin.HandlerFunc {
 			case errors.Is(err, errs.ErrUnauthorized):
 				statusCode = http.StatusUnauthorized
 				message = "Unauthorized"
+			case errors.Is(err, errs.ErrForbidden):
+				statusCode = http.StatusForbidden
+				message = "Forbidden"
 			case errors.Is(err, errs.ErrInvalidUserId):
 				statusCode = http.StatusBadRequest
 				message = "Invalid UserId"
+			case errors.Is(err, errs.ErrInvalidProfileId):
+				statusCode = http.StatusBadRequest
+				message = "Invalid profile id"
+			case errors.Is(err, errs.ErrProfileDeleted):
+				statusCode = http.StatusConflict
+				message = "Profile is deleted, restore it instead"
+			case errors.Is(err, errs.ErrProfileNotDeleted):
+				statusCode = http.StatusConflict
+				message = "Profile is not deleted"
+			case errors.Is(err, errs.ErrRestorePeriodExpired):
+				statusCode = http.StatusGone
+				message = "Profile restore period has expired"
+			case errors.Is(err, errs.ErrInvalidCursor):
+				statusCode = http.StatusBadRequest
+				message = "Invalid pagination cursor"
+			case errors.Is(err, errs.ErrVersionConflict):
+				statusCode = http.StatusPreconditionFailed
+				message = "Profile has been modified, reload it and retry"
+			case errors.Is(err, errs.ErrPreconditionRequired):
+				statusCode = http.StatusPreconditionRequired
+				message = "If-Match header with profile ETag is required"
+			case errors.Is(err, errs.ErrInvalidIdempotencyKey):
+				statusCode = http.StatusBadRequest
+				message = "Idempotency-Key must not be longer than 255 characters"
+			case errors.Is(err, errs.ErrIdempotencyKeyReused):
+				statusCode = http.StatusUnprocessableEntity
+				message = "Idempotency-Key is already used with a different request"
+			case errors.Is(err, errs.ErrIdempotencyKeyInProgress):
+				statusCode = http.StatusConflict
+				message = "Request with this Idempotency-Key is still in progress"
+			case errors.Is(err, errs.ErrRequestBodyTooLarge):
+				statusCode = http.StatusRequestEntityTooLarge
+				message = "Request body is too large"
 			case errors.Is(err, errs.ErrProfileNotFound):
 				statusCode = http.StatusNotFound
 				message = "Profile not found"
@@ -52,6 +88,27 @@ func ErrorHandler() gin.HandlerFunc {
 			case errors.Is(err, errs.ErrTokenExpired):
 				statusCode = http.StatusUnauthorized
 				message = "Token is expired"
+			case errors.Is(err, errs.ErrTokenMissing):
+				statusCode = http.StatusUnauthorized
+				message = "Access token is missing"
+			case errors.Is(err, errs.ErrTokenMalformed):
+				statusCode = http.StatusUnauthorized
+				message = "Authorization header must use Bearer scheme"
+			case errors.Is(err, errs.ErrTokenRevoked):
+				statusCode = http.StatusUnauthorized
+				message = "Token has been revoked"
+			case errors.Is(err, errs.ErrTokenNotYetValid):
+				statusCode = http.StatusUnauthorized
+				message = "Token is not valid yet"
+			case errors.Is(err, errs.ErrTokenIssuerInvalid):
+				statusCode = http.StatusUnauthorized
+				message = "Token issuer is not trusted"
+			case errors.Is(err, errs.ErrTokenAudienceInvalid):
+				statusCode = http.StatusUnauthorized
+				message = "Token is not intended for this service"
+			case errors.Is(err, errs.ErrTokenClaimMissing):
+				statusCode = http.StatusUnauthorized
+				message = "Token is missing required claim"
 			case errors.Is(err, errs.ErrCreateUserProfile):
 				statusCode = http.StatusBadRequest
 				message = "User profile is invalid"
@@ -61,6 +118,48 @@ func ErrorHandler() gin.HandlerFunc {
 			case errors.Is(err, errs.ErrProfileAlreadyExists):
 				statusCode = http.StatusBadRequest
 				message = "Profile already exists"
+			case errors.Is(err, errs.ErrInvalidCartItemId):
+				statusCode = http.StatusBadRequest
+				message = "Invalid cart item id"
+			case errors.Is(err, errs.ErrCartItemNotFound):
+				statusCode = http.StatusNotFound
+				message = "Cart item not found"
+			case errors.Is(err, errs.ErrProductNotFound):
+				statusCode = http.StatusNotFound
+				message = "Product not found"
+			case errors.Is(err, errs.ErrGetProductPrice):
+				statusCode = http.StatusBadGateway
+				message = "Product catalog is unavailable"
+			case errors.Is(err, errs.ErrAddCartItem):
+				statusCode = http.StatusBadRequest
+				message = "Error add cart item"
+			case errors.Is(err, errs.ErrUpdateCartItem):
+				statusCode = http.StatusBadRequest
+				message = "Error update cart item"
+			case errors.Is(err, errs.ErrDeleteCartItem):
+				statusCode = http.StatusBadRequest
+				message = "Error delete cart item"
+			case errors.Is(err, errs.ErrInvalidCardId):
+				statusCode = http.StatusBadRequest
+				message = "Invalid bank card id"
+			case errors.Is(err, errs.ErrCardNotFound):
+				statusCode = http.StatusNotFound
+				message = "Bank card not found"
+			case errors.Is(err, errs.ErrCardAlreadyExists):
+				statusCode = http.StatusConflict
+				message = "Bank card already added"
+			case errors.Is(err, errs.ErrCardTokenNotFound):
+				statusCode = http.StatusNotFound
+				message = "Card token not found"
+			case errors.Is(err, errs.ErrCreateCard):
+				statusCode = http.StatusBadRequest
+				message = "Error create bank card"
+			case errors.Is(err, errs.ErrUpdateCard):
+				statusCode = http.StatusBadRequest
+				message = "Error update bank card"
+			case errors.Is(err, errs.ErrDeleteCard):
+				statusCode = http.StatusBadRequest
+				message = "Error delete bank card"
 			case errors.As(err, &validationErrs): // Проверяем, является ли err ошибкой валидации
 				statusCode = http.StatusBadRequest
 				message = "Validation error"
@@ -96,7 +195,7 @@ func ErrorHandler() gin.HandlerFunc {
 // validationErrorMessage формирует читаемое сообщение ошибки
 func validationErrorMessage(fe validator.FieldError) string {
 	switch fe.Tag() {
-	case "required":
+	case "required", "required_with", "required_without":
 		return "is required"
 	case "min":
 		return "must be at least " + fe.Param()
@@ -104,6 +203,14 @@ func validationErrorMessage(fe validator.FieldError) string {
 		return "must be at most " + fe.Param()
 	case "gt":
 		return "must be greater than " + fe.Param()
+	case "oneof":
+		return "must be one of: " + fe.Param()
+	case "luhn":
+		return "is not a valid card number"
+	case "card_brand":
+		return "belongs to an unsupported card brand"
+	case "future_expiry":
+		return "must be a future date in MM/YY format"
 	default:
 		return "is invalid"
 	}
@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	logger "github.com/sirupsen/logrus"
+
+	"service-user/internal/app/errs"
+	"service-user/internal/app/models"
+)
+
+// IdempotencyKeyHeader - заголовок с ключом идемпотентности, выбирается клиентом на каждую операцию
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader - отмечает ответ, повторенный по Idempotency-Key
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+const maxIdempotencyKeyLength = 255
+
+// maxIdempotentBodyBytes - максимальный размер тела запроса с Idempotency-Key, тело читается в память для хеша
+const maxIdempotentBodyBytes = 1 << 20
+
+// replayedHeaders - заголовки ответа, которые сохраняются и повторяются вместе с телом
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+// IdempotencyStore - хранилище ответов на запросы с Idempotency-Key. Запрос занимает ключ со своим
+// токеном reservation, сохранить ответ или освободить ключ можно только с этим токеном
+type IdempotencyStore interface {
+	ReserveIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, reservation uuid.UUID, requestHash string) (*models.IdempotentResponse, error)
+	SaveIdempotentResponse(ctx context.Context, userID uuid.UUID, key string, reservation uuid.UUID, response models.IdempotentResponse) error
+	ReleaseIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, reservation uuid.UUID) error
+}
+
+// Idempotency повторяет сохраненный ответ на изменяющий запрос с уже использованным Idempotency-Key
+// этого пользователя. Ключ с другим запросом отклоняется с 422, ключ выполняющегося запроса - с 409.
+// Сохраняются только успешные ответы: после ошибки или паники запрос с тем же ключом выполняется заново.
+// Подключается после AuthMiddleware
+func Idempotency(store IdempotencyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		userID, ok := c.Get("user_id")
+		if key == "" || !ok || !isMutating(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.Error(errs.ErrInvalidIdempotencyKey)
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodyBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				err = errs.ErrRequestBodyTooLarge
+			}
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		owner := userID.(uuid.UUID)
+		reservation := uuid.New()
+		stored, err := store.ReserveIdempotencyKey(c, owner, key, reservation, requestHash(c.Request, body))
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		if stored != nil {
+			for name, value := range stored.Headers {
+				c.Header(name, value)
+			}
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(stored.StatusCode, stored.Headers["Content-Type"], stored.Body)
+			c.Abort()
+			return
+		}
+
+		// Клиент мог не дождаться ответа, но ключ все равно нужно сохранить или освободить
+		ctx := context.WithoutCancel(c.Request.Context())
+
+		// Ключ освобождается при любом выходе без сохраненного ответа, в том числе при панике обработчика
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			if err := store.ReleaseIdempotencyKey(ctx, owner, key, reservation); err != nil {
+				logger.Errorf("Error while releasing idempotency key: %v", err)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// Ошибки ErrorHandler отдает уже после этого middleware, поэтому ответ с ошибкой здесь еще не записан
+		if len(c.Errors) > 0 || recorder.Status() >= http.StatusInternalServerError {
+			return
+		}
+		// Запрос выполнен: если ответ не сохранится, ключ остается занятым до конца аренды,
+		// иначе повтор выполнил бы запрос второй раз
+		completed = true
+
+		response := models.IdempotentResponse{
+			StatusCode: recorder.Status(),
+			Headers:    make(map[string]string),
+			Body:       recorder.body.Bytes(),
+		}
+		for _, name := range replayedHeaders {
+			if value := recorder.Header().Get(name); value != "" {
+				response.Headers[name] = value
+			}
+		}
+		if err = store.SaveIdempotentResponse(ctx, owner, key, reservation, response); err != nil {
+			logger.Errorf("Error while saving idempotent response: %v", err)
+		}
+	}
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// requestHash - SHA-256 от метода, адреса и тела запроса
+func requestHash(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder - копирует тело ответа, чтобы сохранить его для повторов
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,284 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"service-user/internal/app/errs"
+	"service-user/internal/app/models"
+)
+
+// memoryStore - хранилище ключей в памяти с теми же правилами, что и сервис идемпотентности
+type memoryStore struct {
+	mu       sync.Mutex
+	records  map[string]*memoryRecord
+	released int
+}
+
+type memoryRecord struct {
+	requestHash string
+	reservation uuid.UUID
+	response    *models.IdempotentResponse
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{records: make(map[string]*memoryRecord)}
+}
+
+func (s *memoryStore) ReserveIdempotencyKey(_ context.Context, userID uuid.UUID, key string, reservation uuid.UUID, requestHash string) (*models.IdempotentResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[userID.String()+key]
+	if !ok {
+		s.records[userID.String()+key] = &memoryRecord{requestHash: requestHash, reservation: reservation}
+		return nil, nil
+	}
+	if record.requestHash != requestHash {
+		return nil, errs.ErrIdempotencyKeyReused
+	}
+	if record.response == nil {
+		return nil, errs.ErrIdempotencyKeyInProgress
+	}
+	return record.response, nil
+}
+
+func (s *memoryStore) SaveIdempotentResponse(_ context.Context, userID uuid.UUID, key string, reservation uuid.UUID, response models.IdempotentResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if record, ok := s.records[userID.String()+key]; ok && record.reservation == reservation && record.response == nil {
+		record.response = &response
+	}
+	return nil
+}
+
+func (s *memoryStore) ReleaseIdempotencyKey(_ context.Context, userID uuid.UUID, key string, reservation uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if record, ok := s.records[userID.String()+key]; ok && record.reservation == reservation && record.response == nil {
+		delete(s.records, userID.String()+key)
+		s.released++
+	}
+	return nil
+}
+
+// takeOver - ключ занимает другой запрос, как после окончания аренды
+func (s *memoryStore) takeOver(userID uuid.UUID, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[userID.String()+key].reservation = uuid.New()
+}
+
+// idempotencyTest - роутер с Idempotency и счетчиком выполнений обработчиков
+type idempotencyTest struct {
+	router *gin.Engine
+	store  *memoryStore
+	userID uuid.UUID
+
+	mu    sync.Mutex
+	calls int
+}
+
+func newIdempotencyTest() *idempotencyTest {
+	gin.SetMode(gin.TestMode)
+	it := &idempotencyTest{router: gin.New(), store: newMemoryStore(), userID: uuid.New()}
+	it.router.Use(ErrorHandler(), func(c *gin.Context) {
+		c.Set("user_id", it.userID)
+		c.Next()
+	}, Idempotency(it.store))
+
+	it.router.POST("/items", func(c *gin.Context) {
+		it.call()
+		c.Header("Location", "/items/1")
+		c.JSON(http.StatusCreated, gin.H{"id": uuid.NewString()})
+	})
+	it.router.POST("/fail", func(c *gin.Context) {
+		it.call()
+		c.Error(errs.ErrProfileNotFound)
+	})
+	it.router.POST("/panic", func(c *gin.Context) {
+		it.call()
+		panic("handler failed")
+	})
+	return it
+}
+
+func (it *idempotencyTest) call() {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	it.calls++
+}
+
+func (it *idempotencyTest) callCount() int {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	return it.calls
+}
+
+func (it *idempotencyTest) do(path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	it.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplaysResponse(t *testing.T) {
+	it := newIdempotencyTest()
+
+	first := it.do("/items", "key-1", `{"a":1}`)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first request: status %d, want 201", first.Code)
+	}
+	second := it.do("/items", "key-1", `{"a":1}`)
+	if second.Code != http.StatusCreated {
+		t.Fatalf("replay: status %d, want 201", second.Code)
+	}
+	if second.Body.String() != first.Body.String() {
+		t.Fatalf("replay body = %s, want %s", second.Body, first.Body)
+	}
+	if second.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatal("replay is not marked with Idempotent-Replayed")
+	}
+	if got := second.Header().Get("Location"); got != "/items/1" {
+		t.Fatalf("replay Location = %q, want /items/1", got)
+	}
+	if first.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Fatal("first response is marked as replayed")
+	}
+	if calls := it.callCount(); calls != 1 {
+		t.Fatalf("handler called %d times, want 1", calls)
+	}
+
+	// Без ключа запрос выполняется каждый раз
+	it.do("/items", "", `{"a":1}`)
+	it.do("/items", "", `{"a":1}`)
+	if calls := it.callCount(); calls != 3 {
+		t.Fatalf("handler called %d times, want 3", calls)
+	}
+}
+
+func TestIdempotencyRejectsDifferentRequest(t *testing.T) {
+	it := newIdempotencyTest()
+
+	it.do("/items", "key-1", `{"a":1}`)
+	if w := it.do("/items", "key-1", `{"a":2}`); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("same key with another body: status %d, want 422", w.Code)
+	}
+	if w := it.do("/fail", "key-1", `{"a":1}`); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("same key with another path: status %d, want 422", w.Code)
+	}
+	if calls := it.callCount(); calls != 1 {
+		t.Fatalf("handler called %d times, want 1", calls)
+	}
+}
+
+func TestIdempotencyRejectsRequestInProgress(t *testing.T) {
+	it := newIdempotencyTest()
+	started, finish := make(chan struct{}), make(chan struct{})
+	it.router.POST("/slow", func(c *gin.Context) {
+		close(started)
+		<-finish
+		c.Status(http.StatusNoContent)
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- it.do("/slow", "key-1", `{}`) }()
+	<-started
+
+	if w := it.do("/slow", "key-1", `{}`); w.Code != http.StatusConflict {
+		t.Fatalf("request while first is in progress: status %d, want 409", w.Code)
+	}
+	close(finish)
+	if w := <-done; w.Code != http.StatusNoContent {
+		t.Fatalf("first request: status %d, want 204", w.Code)
+	}
+	if w := it.do("/slow", "key-1", `{}`); w.Code != http.StatusNoContent || w.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatalf("request after first finished: status %d, replayed %q", w.Code, w.Header().Get(IdempotentReplayedHeader))
+	}
+}
+
+func TestIdempotencyReleasesKeyOnError(t *testing.T) {
+	it := newIdempotencyTest()
+
+	for i := 1; i <= 2; i++ {
+		if w := it.do("/fail", "key-1", `{}`); w.Code != http.StatusNotFound {
+			t.Fatalf("attempt %d: status %d, want 404", i, w.Code)
+		}
+		if calls := it.callCount(); calls != i {
+			t.Fatalf("attempt %d: handler called %d times, want %d", i, calls, i)
+		}
+	}
+	if it.store.released != 2 {
+		t.Fatalf("key released %d times, want 2", it.store.released)
+	}
+}
+
+func TestIdempotencyReleasesKeyOnPanic(t *testing.T) {
+	it := newIdempotencyTest()
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("handler panic was swallowed")
+			}
+		}()
+		it.do("/panic", "key-1", `{}`)
+	}()
+
+	if it.store.released != 1 {
+		t.Fatalf("key released %d times after panic, want 1", it.store.released)
+	}
+	// Ключ свободен, повтор выполняет запрос, а не получает 409
+	func() {
+		defer func() { _ = recover() }()
+		it.do("/panic", "key-1", `{}`)
+	}()
+	if calls := it.callCount(); calls != 2 {
+		t.Fatalf("handler called %d times, want 2", calls)
+	}
+}
+
+func TestIdempotencyLimitsBody(t *testing.T) {
+	it := newIdempotencyTest()
+
+	body := `{"a":"` + strings.Repeat("x", maxIdempotentBodyBytes) + `"}`
+	if w := it.do("/items", "key-1", body); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("oversized body: status %d, want 413", w.Code)
+	}
+	if calls := it.callCount(); calls != 0 {
+		t.Fatalf("handler called %d times, want 0", calls)
+	}
+}
+
+func TestIdempotencyKeepsReservationOfAnotherRequest(t *testing.T) {
+	it := newIdempotencyTest()
+	// Аренда истекла, пока запрос выполнялся, и ключ занял повтор запроса
+	it.router.POST("/expired", func(c *gin.Context) {
+		it.call()
+		it.store.takeOver(it.userID, "key-1")
+		c.Error(errs.ErrProfileNotFound)
+	})
+
+	if w := it.do("/expired", "key-1", `{}`); w.Code != http.StatusNotFound {
+		t.Fatalf("first request: status %d, want 404", w.Code)
+	}
+	if it.store.released != 0 {
+		t.Fatal("request released a key reserved by another request")
+	}
+	if w := it.do("/expired", "key-1", `{}`); w.Code != http.StatusConflict {
+		t.Fatalf("request while the key is reserved by another request: status %d, want 409", w.Code)
+	}
+	if calls := it.callCount(); calls != 1 {
+		t.Fatalf("handler called %d times, want 1", calls)
+	}
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	logger "github.com/sirupsen/logrus"
+
+	"service-user/internal/app/errs"
+	"service-user/internal/app/models"
+)
+
+// claimsKey - ключ, под которым AuthMiddleware кладет claims токена в контекст
+const claimsKey = "claims"
+
+// ClaimsFromContext - claims токена текущего запроса
+func ClaimsFromContext(c *gin.Context) (models.AccessClaims, bool) {
+	raw, exists := c.Get(claimsKey)
+	if !exists {
+		return models.AccessClaims{}, false
+	}
+	claims, ok := raw.(models.AccessClaims)
+	return claims, ok
+}
+
+// ScopeChecker - проверка scope токена. Пока сервис авторизации выдает scope не всем токенам,
+// проверка включается настройкой auth.enforce_scopes, до этого недостающие scope только пишутся в лог
+type ScopeChecker struct {
+	enforce bool
+}
+
+func NewScopeChecker(enforce bool) *ScopeChecker {
+	return &ScopeChecker{enforce: enforce}
+}
+
+// Require пропускает запрос, только если токену выданы все перечисленные scope.
+// Должен стоять после AuthMiddleware
+func (s *ScopeChecker) Require(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := ClaimsFromContext(c)
+		if !ok {
+			c.Error(errs.ErrUnauthorized)
+			c.Abort()
+			return
+		}
+
+		for _, scope := range scopes {
+			if claims.HasScope(scope) {
+				continue
+			}
+			if !s.enforce {
+				logger.Warnf("User %v has no scope %q for %s %s, allowed until scopes are enforced",
+					claims.UserID, scope, c.Request.Method, c.FullPath())
+				continue
+			}
+			logger.Debugf("User %v has no scope %q", claims.UserID, scope)
+			c.Error(errs.ErrForbidden)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireRole пропускает запрос, если у пользователя есть хотя бы одна из перечисленных ролей.
+// Должен стоять после AuthMiddleware
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := ClaimsFromContext(c)
+		if !ok {
+			c.Error(errs.ErrUnauthorized)
+			c.Abort()
+			return
+		}
+
+		for _, role := range roles {
+			if claims.HasRole(role) {
+				c.Next()
+				return
+			}
+		}
+		logger.Debugf("User %v has none of roles %v", claims.UserID, roles)
+		c.Error(errs.ErrForbidden)
+		c.Abort()
+	}
+}
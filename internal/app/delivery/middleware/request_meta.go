@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"service-user/internal/app/models"
+)
+
+// RequestIDHeader - заголовок с id запроса, сквозной между сервисами
+const RequestIDHeader = "X-Request-ID"
+
+// RequestMeta кладет в контекст запроса его id и адрес клиента.
+// id берется из заголовка X-Request-ID или генерируется и возвращается в ответе
+func RequestMeta() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > 255 {
+			requestID = uuid.NewString()
+		}
+		c.Header(RequestIDHeader, requestID)
+
+		meta := &models.RequestMeta{
+			RequestID: requestID,
+			SourceIP:  c.ClientIP(),
+		}
+		c.Request = c.Request.WithContext(models.WithRequestMeta(c.Request.Context(), meta))
+		c.Next()
+	}
+}
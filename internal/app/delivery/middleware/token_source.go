@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"service-user/internal/app/errs"
+)
+
+// Имена источников токена в конфигурации
+const (
+	TokenSourceCookie = "cookie"
+	TokenSourceBearer = "bearer"
+	TokenSourceHeader = "header"
+)
+
+// TokenSource извлекает access token из запроса.
+// Пустая строка без ошибки - токена в этом источнике нет, нужно смотреть следующий
+type TokenSource func(c *gin.Context) (string, error)
+
+// NewTokenSources собирает источники токена в порядке, заданном в конфигурации
+func NewTokenSources(names []string, cookieName string, headerName string) ([]TokenSource, error) {
+	sources := make([]TokenSource, 0, len(names))
+	for _, name := range names {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case TokenSourceCookie:
+			sources = append(sources, CookieTokenSource(cookieName))
+		case TokenSourceBearer:
+			sources = append(sources, BearerTokenSource())
+		case TokenSourceHeader:
+			sources = append(sources, HeaderTokenSource(headerName))
+		default:
+			return nil, fmt.Errorf("unknown token source: %q", name)
+		}
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no token sources configured")
+	}
+	return sources, nil
+}
+
+// CookieTokenSource - токен из cookie
+func CookieTokenSource(name string) TokenSource {
+	return func(c *gin.Context) (string, error) {
+		cookie, err := c.Cookie(name)
+		if err != nil {
+			return "", nil
+		}
+		return strings.TrimSpace(cookie), nil
+	}
+}
+
+// BearerTokenSource - токен из заголовка Authorization: Bearer <token>
+func BearerTokenSource() TokenSource {
+	return func(c *gin.Context) (string, error) {
+		header := strings.TrimSpace(c.GetHeader("Authorization"))
+		if header == "" {
+			return "", nil
+		}
+
+		scheme, token, found := strings.Cut(header, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+			return "", errs.ErrTokenMalformed
+		}
+		return strings.TrimSpace(token), nil
+	}
+}
+
+// HeaderTokenSource - токен из произвольного заголовка без схемы
+func HeaderTokenSource(name string) TokenSource {
+	return func(c *gin.Context) (string, error) {
+		return strings.TrimSpace(c.GetHeader(name)), nil
+	}
+}
+
+// extractToken - токен из первого источника, в котором он есть
+func extractToken(c *gin.Context, sources []TokenSource) (string, error) {
+	for _, source := range sources {
+		token, err := source(c)
+		if err != nil {
+			return "", err
+		}
+		if token != "" {
+			return token, nil
+		}
+	}
+	return "", errs.ErrTokenMissing
+}
@@ -0,0 +1,17 @@
+package errs
+
+import "errors"
+
+var (
+	ErrInvalidCardId = errors.New("invalid bank card id")
+	ErrCardNotFound  = errors.New("bank card not found")
+	ErrGetCard       = errors.New("error get bank card")
+	ErrCreateCard    = errors.New("error create bank card")
+	ErrUpdateCard    = errors.New("error update bank card")
+	ErrDeleteCard    = errors.New("error delete bank card")
+
+	ErrCardAlreadyExists = errors.New("bank card already added")
+	ErrCardTokenNotFound = errors.New("card token not found")
+	ErrTokenizeCard      = errors.New("error tokenize bank card")
+	ErrDetokenizeCard    = errors.New("error detokenize bank card")
+)
@@ -0,0 +1,14 @@
+package errs
+
+import "errors"
+
+var (
+	ErrInvalidCartItemId = errors.New("invalid cart item id")
+	ErrCartItemNotFound  = errors.New("cart item not found")
+	ErrGetCart           = errors.New("error get cart")
+	ErrAddCartItem       = errors.New("error add cart item")
+	ErrUpdateCartItem    = errors.New("error update cart item")
+	ErrDeleteCartItem    = errors.New("error delete cart item")
+	ErrProductNotFound   = errors.New("product not found")
+	ErrGetProductPrice   = errors.New("error get product price")
+)
@@ -0,0 +1,11 @@
+package errs
+
+import "errors"
+
+var (
+	ErrInvalidIdempotencyKey    = errors.New("invalid idempotency key")
+	ErrIdempotencyKeyReused     = errors.New("idempotency key is reused with a different request")
+	ErrIdempotencyKeyInProgress = errors.New("request with this idempotency key is in progress")
+	ErrIdempotencyStore         = errors.New("error store idempotent response")
+	ErrRequestBodyTooLarge      = errors.New("request body is too large")
+)
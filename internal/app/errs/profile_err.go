@@ -4,15 +4,39 @@ import "errors"
 
 var (
 	ErrUnauthorized         = errors.New("unauthorized")
+	ErrForbidden            = errors.New("forbidden")
 	ErrInvalidUserId        = errors.New("invalid user id")
 	ErrProfileAlreadyExists = errors.New("user profile already exists")
 	ErrCreateUserProfile    = errors.New("error create user-profile")
 	ErrProfileNotFound      = errors.New("profile not found")
 	ErrDeleteUserProfile    = errors.New("error delete user-profile")
 	ErrUpdateUserProfile    = errors.New("error update user-profile")
+	ErrInvalidProfileId     = errors.New("invalid profile id")
+	ErrAdminAction          = errors.New("error record admin action")
+	ErrListProfiles         = errors.New("error list user-profiles")
+	ErrInvalidCursor        = errors.New("invalid pagination cursor")
+	ErrSearchProfiles       = errors.New("error search user-profiles")
+	ErrProfileDeleted       = errors.New("user profile is deleted")
+	ErrProfileNotDeleted    = errors.New("user profile is not deleted")
+	ErrRestorePeriodExpired = errors.New("user profile restore period has expired")
+	ErrRestoreProfile       = errors.New("error restore user-profile")
+	ErrGetAuditHistory      = errors.New("error get audit history")
+	ErrExportPersonalData   = errors.New("error export personal data")
+	ErrEraseProfile         = errors.New("error erase user-profile")
+	ErrProfileAudit         = errors.New("error record profile audit")
+	ErrRecordEvent          = errors.New("error record domain event")
+	ErrVersionConflict      = errors.New("user profile version conflict")
+	ErrPreconditionRequired = errors.New("if-match header is required")
 )
 
 var (
-	ErrTokenExpired = errors.New("token has expired")
-	ErrTokenInvalid = errors.New("token invalid")
+	ErrTokenExpired   = errors.New("token has expired")
+	ErrTokenInvalid   = errors.New("token invalid")
+	ErrTokenMissing   = errors.New("token not provided")
+	ErrTokenMalformed = errors.New("malformed authorization header")
+
+	ErrTokenNotYetValid     = errors.New("token is not valid yet")
+	ErrTokenIssuerInvalid   = errors.New("token has invalid issuer")
+	ErrTokenAudienceInvalid = errors.New("token has invalid audience")
+	ErrTokenClaimMissing    = errors.New("token is missing required claim")
 )
@@ -0,0 +1,9 @@
+package errs
+
+import "errors"
+
+var (
+	ErrTokenRevoked   = errors.New("token has been revoked")
+	ErrRevokeToken    = errors.New("error revoke token")
+	ErrGetRevocations = errors.New("error get revoked tokens")
+)
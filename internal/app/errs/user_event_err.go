@@ -0,0 +1,8 @@
+package errs
+
+import "errors"
+
+var (
+	ErrInvalidEvent = errors.New("invalid event")
+	ErrProcessEvent = errors.New("error process event")
+)
@@ -0,0 +1,90 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	logger "github.com/sirupsen/logrus"
+)
+
+// Типы доменных событий сервиса
+const (
+	CardExpiring    = "card.expiring"
+	ProfileCreated  = "profile.created"
+	ProfileUpdated  = "profile.updated"
+	ProfileDeleted  = "profile.deleted"
+	ProfileRestored = "profile.restored"
+
+	CartItemAdded   = "cart.item_added"
+	CartItemUpdated = "cart.item_updated"
+	CartItemRemoved = "cart.item_removed"
+	CartCleared     = "cart.cleared"
+)
+
+// Event - доменное событие
+type Event struct {
+	ID         uuid.UUID   `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Payload    interface{} `json:"payload"`
+	// Key - ключ упорядочивания (user_id): события одного пользователя попадают в одну партицию
+	Key string `json:"-"`
+}
+
+// NewEvent - событие с новым id и текущим временем
+func NewEvent(eventType string, payload interface{}) Event {
+	return Event{
+		ID:         uuid.New(),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		Payload:    payload,
+	}
+}
+
+// ProfilePayload - данные событий профиля. В profile.deleted передаются только id,
+// erased означает, что персональные данные пользователя стерты по запросу
+type ProfilePayload struct {
+	ProfileID uuid.UUID `json:"profile_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	FirstName string    `json:"first_name,omitempty"`
+	LastName  string    `json:"last_name,omitempty"`
+	City      string    `json:"city,omitempty"`
+	Erased    bool      `json:"erased,omitempty"`
+}
+
+// CartPayload - данные событий корзины. В cart.cleared передаются только id
+type CartPayload struct {
+	UserID    uuid.UUID  `json:"user_id"`
+	CartID    uuid.UUID  `json:"cart_id"`
+	ItemID    *uuid.UUID `json:"item_id,omitempty"`
+	ProductID *uuid.UUID `json:"product_id,omitempty"`
+	Quantity  int        `json:"quantity,omitempty"`
+	Price     float64    `json:"price,omitempty"`
+}
+
+// Publisher - отправка доменных событий подписчикам
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// LogPublisher - публикует события в лог, пока в окружении нет брокера
+type LogPublisher struct{}
+
+func NewLogPublisher() *LogPublisher {
+	return &LogPublisher{}
+}
+
+func (p *LogPublisher) Publish(_ context.Context, event Event) error {
+	payload, err := json.Marshal(event.Payload)
+	if err != nil {
+		return err
+	}
+	logger.WithFields(logger.Fields{
+		"event_id":    event.ID,
+		"event_type":  event.Type,
+		"occurred_at": event.OccurredAt,
+	}).Infof("Domain event: %s", payload)
+	return nil
+}
@@ -0,0 +1,97 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"service-user/internal/configs"
+)
+
+// EnvelopeSchemaVersion - версия схемы конверта. Увеличивается при несовместимом изменении
+// конверта или данных событий, подписчики выбирают разбор по версии
+const EnvelopeSchemaVersion = 1
+
+// Заголовки сообщения, по ним подписчик фильтрует события без разбора тела
+const (
+	HeaderEventType     = "event-type"
+	HeaderSchemaVersion = "schema-version"
+)
+
+// Envelope - конверт события в сообщении Kafka
+type Envelope struct {
+	SchemaVersion int `json:"schema_version"`
+	Event
+}
+
+// MessageWriter - запись сообщений в брокер, реализуется kafka.Writer и kafkatest.Broker
+type MessageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// KafkaPublisher - публикация событий в Kafka, топик выбирается по типу события
+type KafkaPublisher struct {
+	writer       MessageWriter
+	topics       map[string]string // тип события -> топик
+	defaultTopic string
+}
+
+func NewKafkaPublisher(writer MessageWriter, routes []configs.TopicRoute, defaultTopic string) *KafkaPublisher {
+	topics := make(map[string]string)
+	for _, route := range routes {
+		for _, eventType := range route.Events {
+			topics[eventType] = route.Topic
+		}
+	}
+	return &KafkaPublisher{writer: writer, topics: topics, defaultTopic: defaultTopic}
+}
+
+// NewKafkaWriter - синхронная запись с подтверждением всех реплик. Партиция выбирается по ключу
+// тем же murmur2, что и в Java клиенте, поэтому ключ попадает в ту же партицию у всех продюсеров
+func NewKafkaWriter(cfg *configs.KafkaConfig) *kafka.Writer {
+	return &kafka.Writer{
+		Addr:         kafka.TCP(cfg.Brokers...),
+		Balancer:     kafka.Murmur2Balancer{},
+		RequiredAcks: kafka.RequireAll,
+		WriteTimeout: cfg.WriteTimeout,
+		// События отправляются по одному, ждать заполнения пачки незачем
+		BatchTimeout: 10 * time.Millisecond,
+		Transport:    &kafka.Transport{ClientID: cfg.ClientID},
+	}
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, event Event) error {
+	topic, ok := p.topics[event.Type]
+	if !ok {
+		topic = p.defaultTopic
+	}
+	if topic == "" {
+		return fmt.Errorf("no kafka topic for event type %s", event.Type)
+	}
+
+	value, err := json.Marshal(Envelope{SchemaVersion: EnvelopeSchemaVersion, Event: event})
+	if err != nil {
+		return err
+	}
+
+	return p.writer.WriteMessages(ctx, kafka.Message{
+		Topic: topic,
+		Key:   []byte(event.Key),
+		Value: value,
+		Headers: []kafka.Header{
+			{Key: HeaderEventType, Value: []byte(event.Type)},
+			{Key: HeaderSchemaVersion, Value: []byte(strconv.Itoa(EnvelopeSchemaVersion))},
+		},
+		Time: event.OccurredAt,
+	})
+}
+
+// Close дожидается отправки сообщений и закрывает соединения с брокером
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
@@ -0,0 +1,231 @@
+package events_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+
+	"service-user/internal/app/events"
+	"service-user/internal/app/events/kafkatest"
+	"service-user/internal/configs"
+)
+
+const (
+	profileTopic = "user.profile.events.v1"
+	cartTopic    = "user.cart.events.v1"
+	defaultTopic = "user.events.v1"
+	partitions   = 4
+)
+
+var routes = []configs.TopicRoute{
+	{Topic: profileTopic, Events: []string{events.ProfileCreated, events.ProfileUpdated, events.ProfileDeleted}},
+	{Topic: cartTopic, Events: []string{events.CartItemAdded, events.CartCleared}},
+}
+
+func newPublisher(t *testing.T) (*events.KafkaPublisher, *kafkatest.Broker) {
+	t.Helper()
+	broker := kafkatest.NewBroker(partitions, profileTopic, cartTopic, defaultTopic)
+	return events.NewKafkaPublisher(broker, routes, defaultTopic), broker
+}
+
+func userEvent(eventType string, userID uuid.UUID, payload interface{}) events.Event {
+	event := events.NewEvent(eventType, payload)
+	event.Key = userID.String()
+	return event
+}
+
+func TestKafkaPublisherRoutesByEventType(t *testing.T) {
+	publisher, broker := newPublisher(t)
+	userID := uuid.New()
+
+	tests := []struct {
+		eventType string
+		topic     string
+	}{
+		{eventType: events.ProfileCreated, topic: profileTopic},
+		{eventType: events.ProfileDeleted, topic: profileTopic},
+		{eventType: events.CartItemAdded, topic: cartTopic},
+		{eventType: events.CardExpiring, topic: defaultTopic}, // без маршрута
+	}
+	for _, tt := range tests {
+		if err := publisher.Publish(context.Background(), userEvent(tt.eventType, userID, nil)); err != nil {
+			t.Fatalf("Publish(%s): %v", tt.eventType, err)
+		}
+	}
+
+	want := map[string][]string{}
+	for _, tt := range tests {
+		want[tt.topic] = append(want[tt.topic], tt.eventType)
+	}
+	for topic, types := range want {
+		msgs := broker.Messages(topic)
+		if len(msgs) != len(types) {
+			t.Fatalf("topic %s: %d messages, want %d", topic, len(msgs), len(types))
+		}
+		for i, msg := range msgs {
+			if got := header(msg, events.HeaderEventType); got != types[i] {
+				t.Errorf("topic %s message %d: event-type = %q, want %q", topic, i, got, types[i])
+			}
+			if got := header(msg, events.HeaderSchemaVersion); got != "1" {
+				t.Errorf("topic %s message %d: schema-version = %q, want 1", topic, i, got)
+			}
+		}
+	}
+}
+
+func TestKafkaPublisherKeysByUser(t *testing.T) {
+	publisher, broker := newPublisher(t)
+	users := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+
+	// События пользователей перемешаны, у каждого пользователя свой порядок
+	var published []events.Event
+	for i := 0; i < 3; i++ {
+		for _, userID := range users {
+			event := userEvent(events.ProfileUpdated, userID, nil)
+			if err := publisher.Publish(context.Background(), event); err != nil {
+				t.Fatalf("Publish: %v", err)
+			}
+			published = append(published, event)
+		}
+	}
+
+	partitionOf := map[string]int{}
+	for _, msg := range broker.Messages(profileTopic) {
+		key := string(msg.Key)
+		want := kafka.Murmur2Balancer{}.Balance(kafka.Message{Key: msg.Key}, 0, 1, 2, 3)
+		if msg.Partition != want {
+			t.Errorf("key %s: partition %d, want murmur2 partition %d", key, msg.Partition, want)
+		}
+		if p, ok := partitionOf[key]; ok && p != msg.Partition {
+			t.Errorf("key %s is split between partitions %d and %d", key, p, msg.Partition)
+		}
+		partitionOf[key] = msg.Partition
+	}
+	for _, userID := range users {
+		if _, ok := partitionOf[userID.String()]; !ok {
+			t.Fatalf("no messages keyed by user %s", userID)
+		}
+	}
+
+	// Внутри партиции события пользователя идут в порядке публикации
+	for _, userID := range users {
+		var want []uuid.UUID
+		for _, event := range published {
+			if event.Key == userID.String() {
+				want = append(want, event.ID)
+			}
+		}
+		var got []uuid.UUID
+		for _, msg := range broker.Partition(profileTopic, partitionOf[userID.String()]) {
+			if string(msg.Key) != userID.String() {
+				continue
+			}
+			envelope, err := kafkatest.DecodeEnvelope(msg)
+			if err != nil {
+				t.Fatalf("DecodeEnvelope: %v", err)
+			}
+			got = append(got, envelope.ID)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("user %s: %d messages, want %d", userID, len(got), len(want))
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("user %s: message %d is %s, want %s", userID, i, got[i], want[i])
+			}
+		}
+	}
+}
+
+func TestKafkaPublisherEnvelope(t *testing.T) {
+	publisher, broker := newPublisher(t)
+	userID, profileID := uuid.New(), uuid.New()
+	event := userEvent(events.ProfileCreated, userID, events.ProfilePayload{
+		ProfileID: profileID,
+		UserID:    userID,
+		FirstName: "Иван",
+		City:      "Москва",
+	})
+	if err := publisher.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	msgs := broker.Messages(profileTopic)
+	if len(msgs) != 1 {
+		t.Fatalf("%d messages, want 1", len(msgs))
+	}
+	if !msgs[0].Time.Equal(event.OccurredAt) {
+		t.Errorf("message time = %v, want %v", msgs[0].Time, event.OccurredAt)
+	}
+
+	envelope, err := kafkatest.DecodeEnvelope(msgs[0])
+	if err != nil {
+		t.Fatalf("DecodeEnvelope: %v", err)
+	}
+	if envelope.SchemaVersion != events.EnvelopeSchemaVersion {
+		t.Errorf("schema_version = %d, want %d", envelope.SchemaVersion, events.EnvelopeSchemaVersion)
+	}
+	if envelope.ID != event.ID || envelope.Type != event.Type || !envelope.OccurredAt.Equal(event.OccurredAt) {
+		t.Errorf("envelope = %+v, want event %+v", envelope.Event, event)
+	}
+	if envelope.Key != userID.String() {
+		t.Errorf("key = %q, want %q", envelope.Key, userID)
+	}
+
+	var payload events.ProfilePayload
+	if err = json.Unmarshal(envelope.Payload.(json.RawMessage), &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload != event.Payload.(events.ProfilePayload) {
+		t.Errorf("payload = %+v, want %+v", payload, event.Payload)
+	}
+}
+
+func TestKafkaPublisherWriteErrors(t *testing.T) {
+	publisher, broker := newPublisher(t)
+	userID := uuid.New()
+
+	broker.FailNext(kafka.LeaderNotAvailable)
+	err := publisher.Publish(context.Background(), userEvent(events.ProfileCreated, userID, nil))
+	if !errors.Is(err, kafka.LeaderNotAvailable) {
+		t.Fatalf("Publish with failing broker: err = %v, want LeaderNotAvailable", err)
+	}
+	if msgs := broker.Messages(profileTopic); len(msgs) != 0 {
+		t.Fatalf("failed write stored %d messages", len(msgs))
+	}
+
+	// Ошибка одноразовая, повтор публикует событие
+	if err = publisher.Publish(context.Background(), userEvent(events.ProfileCreated, userID, nil)); err != nil {
+		t.Fatalf("Publish after failure: %v", err)
+	}
+	if msgs := broker.Messages(profileTopic); len(msgs) != 1 {
+		t.Fatalf("%d messages after retry, want 1", len(msgs))
+	}
+}
+
+func TestKafkaPublisherNoTopic(t *testing.T) {
+	broker := kafkatest.NewBroker(partitions, profileTopic)
+	publisher := events.NewKafkaPublisher(broker, routes, "")
+
+	if err := publisher.Publish(context.Background(), userEvent(events.CardExpiring, uuid.New(), nil)); err == nil {
+		t.Fatal("Publish without route and default topic succeeded")
+	}
+	// Маршрут есть, но топик в брокере не создан
+	err := publisher.Publish(context.Background(), userEvent(events.CartCleared, uuid.New(), nil))
+	if !errors.Is(err, kafka.UnknownTopicOrPartition) {
+		t.Fatalf("Publish to missing topic: err = %v, want UnknownTopicOrPartition", err)
+	}
+}
+
+func header(msg kafka.Message, key string) string {
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,123 @@
+// Package kafkatest - брокер Kafka в памяти процесса для интеграционных тестов публикации событий.
+// Сообщения распределяются по партициям тем же murmur2, что и в events.NewKafkaWriter
+package kafkatest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"service-user/internal/app/events"
+)
+
+// ErrBrokerClosed - запись после Close
+var ErrBrokerClosed = errors.New("kafkatest: broker is closed")
+
+// Broker - топики с партициями в памяти, реализует events.MessageWriter
+type Broker struct {
+	mu         sync.Mutex
+	partitions []int
+	topics     map[string][][]kafka.Message // топик -> партиции -> сообщения по offset
+	failures   []error
+	closed     bool
+}
+
+// NewBroker - брокер с заданными топиками по partitions партиций в каждом.
+// Запись в другой топик отклоняется, как при выключенном автосоздании топиков
+func NewBroker(partitions int, topics ...string) *Broker {
+	b := &Broker{topics: make(map[string][][]kafka.Message)}
+	for i := 0; i < partitions; i++ {
+		b.partitions = append(b.partitions, i)
+	}
+	for _, topic := range topics {
+		b.topics[topic] = make([][]kafka.Message, partitions)
+	}
+	return b
+}
+
+// FailNext - следующие вызовы WriteMessages вернут эти ошибки по одной, сообщения при этом не записываются
+func (b *Broker) FailNext(errs ...error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = append(b.failures, errs...)
+}
+
+// WriteMessages записывает все сообщения или ни одного
+func (b *Broker) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrBrokerClosed
+	}
+	if len(b.failures) > 0 {
+		err := b.failures[0]
+		b.failures = b.failures[1:]
+		return err
+	}
+	for _, msg := range msgs {
+		if _, ok := b.topics[msg.Topic]; !ok {
+			return kafka.UnknownTopicOrPartition
+		}
+	}
+
+	balancer := kafka.Murmur2Balancer{}
+	for _, msg := range msgs {
+		partition := balancer.Balance(msg, b.partitions...)
+		log := b.topics[msg.Topic][partition]
+		msg.Partition = partition
+		msg.Offset = int64(len(log))
+		if msg.Time.IsZero() {
+			msg.Time = time.Now()
+		}
+		b.topics[msg.Topic][partition] = append(log, msg)
+	}
+	return nil
+}
+
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	return nil
+}
+
+// Messages - все сообщения топика: по партициям, внутри партиции по offset
+func (b *Broker) Messages(topic string) []kafka.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var msgs []kafka.Message
+	for _, log := range b.topics[topic] {
+		msgs = append(msgs, log...)
+	}
+	return msgs
+}
+
+// Partition - сообщения одной партиции топика по offset
+func (b *Broker) Partition(topic string, partition int) []kafka.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if partition < 0 || partition >= len(b.topics[topic]) {
+		return nil
+	}
+	return append([]kafka.Message(nil), b.topics[topic][partition]...)
+}
+
+// DecodeEnvelope - разбор конверта события из сообщения, данные события остаются в JSON
+func DecodeEnvelope(msg kafka.Message) (events.Envelope, error) {
+	var payload json.RawMessage
+	envelope := events.Envelope{Event: events.Event{Payload: &payload}}
+	if err := json.Unmarshal(msg.Value, &envelope); err != nil {
+		return events.Envelope{}, err
+	}
+	envelope.Payload = payload
+	envelope.Key = string(msg.Key)
+	return envelope, nil
+}
@@ -0,0 +1,73 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"service-user/internal/configs"
+)
+
+// Способы публикации событий
+const (
+	PublisherLog    = "log"
+	PublisherStdout = "stdout"
+	PublisherFile   = "file"
+	PublisherKafka  = "kafka"
+)
+
+// StreamPublisher - пишет события построчно в JSON, для локального запуска без брокера
+type StreamPublisher struct {
+	mu  sync.Mutex
+	w   io.Writer
+	enc *json.Encoder
+}
+
+func NewStreamPublisher(w io.Writer) *StreamPublisher {
+	return &StreamPublisher{w: w, enc: json.NewEncoder(w)}
+}
+
+// NewFilePublisher - публикация событий в конец файла path
+func NewFilePublisher(path string) (*StreamPublisher, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open events file: %w", err)
+	}
+	return NewStreamPublisher(file), nil
+}
+
+func (p *StreamPublisher) Publish(_ context.Context, event Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.enc.Encode(event)
+}
+
+// Close закрывает файл, stdout не закрывается
+func (p *StreamPublisher) Close() error {
+	if file, ok := p.w.(*os.File); ok && file != os.Stdout {
+		return file.Close()
+	}
+	return nil
+}
+
+// NewPublisher - публикация событий способом из конфигурации
+func NewPublisher(cfg *configs.EventsConfig) (Publisher, error) {
+	switch cfg.Publisher {
+	case PublisherLog:
+		return NewLogPublisher(), nil
+	case PublisherStdout:
+		return NewStreamPublisher(os.Stdout), nil
+	case PublisherFile:
+		return NewFilePublisher(cfg.File)
+	case PublisherKafka:
+		if len(cfg.Kafka.Brokers) == 0 {
+			return nil, fmt.Errorf("kafka brokers are not configured")
+		}
+		return NewKafkaPublisher(NewKafkaWriter(&cfg.Kafka), cfg.Kafka.Topics, cfg.Kafka.DefaultTopic), nil
+	default:
+		return nil, fmt.Errorf("unknown events publisher %q", cfg.Publisher)
+	}
+}
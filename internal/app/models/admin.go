@@ -0,0 +1,19 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// Действия администратора, которые записываются в журнал admin_actions
+const (
+	AdminActionViewProfile   = "profile.view"
+	AdminActionUpdateProfile = "profile.update"
+	AdminActionDeleteProfile = "profile.delete"
+	AdminActionEraseProfile  = "profile.erase"
+)
+
+// ProfileRef - ссылка на профиль по id профиля или по user_id, задано ровно одно из полей
+type ProfileRef struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cart представляет корзину пользователя
+type Cart struct {
+	ID            uuid.UUID `json:"id"`
+	UserProfileID uuid.UUID `json:"user_profile_id" validate:"required"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// CartItem представляет элемент корзины
+type CartItem struct {
+	ID        uuid.UUID `json:"id"`
+	CartID    uuid.UUID `json:"cart_id" validate:"required"`
+	ProductID uuid.UUID `json:"product_id" validate:"required"`
+	Quantity  int       `json:"quantity" validate:"required,min=1"`
+	Price     float64   `json:"price" validate:"required,gt=0"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CartOut - корзина пользователя вместе с товарами
+type CartOut struct {
+	ID            uuid.UUID  `json:"id"`
+	UserProfileID uuid.UUID  `json:"user_profile_id"`
+	Items         []CartItem `json:"items"`
+	TotalPrice    float64    `json:"total_price"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// CartItemInput - товар, добавляемый в корзину. Цена берется из каталога товаров
+type CartItemInput struct {
+	ProductID uuid.UUID `json:"product_id" validate:"required"`
+	Quantity  int       `json:"quantity" validate:"required,min=1"`
+}
+
+func (i *CartItemInput) Validate() error {
+	return validate.Struct(i)
+}
+
+// CartItemQuantityUpdate - изменение количества товара в корзине
+type CartItemQuantityUpdate struct {
+	Quantity int `json:"quantity" validate:"required,min=1"`
+}
+
+func (i *CartItemQuantityUpdate) Validate() error {
+	return validate.Struct(i)
+}
+
+type CartItemIdResponse struct {
+	ID uuid.UUID `json:"id"`
+}
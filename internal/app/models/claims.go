@@ -0,0 +1,28 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AccessClaims - данные access токена, нужные сервису для авторизации запроса
+type AccessClaims struct {
+	UserID    uuid.UUID // sub
+	TokenID   string    // jti
+	IssuedAt  time.Time // iat
+	SessionID string    // sid
+	Roles     []string  // roles
+	Scopes    []string  // scope (через пробел) или scp
+}
+
+// HasRole - есть ли у пользователя роль
+func (c AccessClaims) HasRole(role string) bool {
+	return slices.Contains(c.Roles, role)
+}
+
+// HasScope - выдан ли токену scope
+func (c AccessClaims) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes, scope)
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Форматы выгрузки персональных данных
+const (
+	ExportFormatJSON = "json"
+	ExportFormatZIP  = "zip"
+)
+
+// PersonalDataExportQuery - параметры выгрузки персональных данных
+type PersonalDataExportQuery struct {
+	Format string `form:"format" validate:"omitempty,oneof=json zip"`
+}
+
+func (q *PersonalDataExportQuery) Validate() error {
+	return validate.Struct(q)
+}
+
+// AuditRecord - запись истории действий с профилем
+type AuditRecord struct {
+	Action    string          `json:"action"`
+	Details   json.RawMessage `json:"details,omitempty" swaggertype:"object"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// PersonalDataExport - все данные, которые сервис хранит о пользователе
+type PersonalDataExport struct {
+	GeneratedAt  time.Time            `json:"generated_at"`
+	Profile      UserProfileOut       `json:"profile"`
+	Cart         CartOut              `json:"cart"`
+	Cards        []UserBankCardOut    `json:"cards"` // номера карт маскированы
+	AuditHistory []AuditRecord        `json:"audit_history"`
+	ChangeLog    []ProfileAuditRecord `json:"change_log"`
+}
+
+// Sections - разделы выгрузки для ZIP архива, по одному файлу на раздел
+func (e PersonalDataExport) Sections() map[string]interface{} {
+	return map[string]interface{}{
+		"profile.json": e.Profile,
+		"cart.json":    e.Cart,
+		"cards.json":   e.Cards,
+		"audit.json":   e.AuditHistory,
+		"history.json": e.ChangeLog,
+	}
+}
@@ -0,0 +1,14 @@
+package models
+
+// IdempotentResponse - сохраненный ответ на запрос с Idempotency-Key
+type IdempotentResponse struct {
+	StatusCode int
+	Headers    map[string]string
+	Body       []byte
+}
+
+// IdempotencyRecord - запрос с Idempotency-Key. Response пустой, пока запрос выполняется
+type IdempotencyRecord struct {
+	RequestHash string
+	Response    *IdempotentResponse
+}
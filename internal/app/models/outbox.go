@@ -0,0 +1,19 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxEvent - доменное событие, ожидающее публикации
+type OutboxEvent struct {
+	ID          uuid.UUID
+	Type        string
+	AggregateID uuid.UUID // id профиля или корзины, их события публикуются по порядку
+	Key         string    // ключ сообщения в брокере, user_id
+	Payload     json.RawMessage
+	OccurredAt  time.Time
+	Attempts    int // сколько раз публикация уже не удалась
+}
@@ -33,11 +33,33 @@ func (u *UserProfileInput) Validate() error {
 	return validate.Struct(u)
 }
 
+// Версии профиля из If-Match: NoVersion - заголовка нет, AnyVersion - "*", подходит любая версия
+const (
+	NoVersion  int64 = 0
+	AnyVersion int64 = -1
+)
+
+// ProfileVersion - версия профиля из ETag. Профиль, созданный заново после удаления, снова начинается
+// с первой версии, поэтому версия имеет смысл только вместе с id профиля
+type ProfileVersion struct {
+	ProfileID uuid.UUID
+	Version   int64
+}
+
+// Matches - подходит ли профиль profileID версии version под версию из If-Match
+func (v ProfileVersion) Matches(profileID uuid.UUID, version int64) bool {
+	if v.Version == NoVersion || v.Version == AnyVersion {
+		return true
+	}
+	return v.ProfileID == profileID && v.Version == version
+}
+
 type UserProfileUpdate struct {
-	UserID    uuid.UUID `json:"-"` // Берется из JWT
-	FirstName string    `json:"first_name" validate:"omitempty,min=2,max=50"`
-	LastName  string    `json:"last_name" validate:"omitempty,min=2,max=50"`
-	City      string    `json:"city" validate:"omitempty,min=2,max=100"`
+	UserID    uuid.UUID      `json:"-"` // Берется из JWT
+	IfMatch   ProfileVersion `json:"-"` // Берется из If-Match, изменение отклоняется, если профиль уже другой версии
+	FirstName string         `json:"first_name" validate:"omitempty,min=2,max=50"`
+	LastName  string         `json:"last_name" validate:"omitempty,min=2,max=50"`
+	City      string         `json:"city" validate:"omitempty,min=2,max=100"`
 }
 
 func (u *UserProfileUpdate) Validate() error {
@@ -52,26 +74,9 @@ type UserProfileOut struct {
 	City      string    `json:"city"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	Version   int64     `json:"-"` // Отдается в заголовке ETag
 }
 
 type ProfileIdResponse struct {
 	ID uuid.UUID `json:"id"`
 }
-
-// Cart представляет корзину пользователя
-type Cart struct {
-	ID            uuid.UUID `json:"id"`
-	UserProfileID uuid.UUID `json:"user_profile_id" validate:"required"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
-}
-
-// CartItem представляет элемент корзины
-type CartItem struct {
-	ID        uuid.UUID `json:"id"`
-	CartID    uuid.UUID `json:"cart_id" validate:"required"`
-	ProductID uuid.UUID `json:"product_id" validate:"required"`
-	Quantity  int       `json:"quantity" validate:"required,min=1"`
-	Price     float64   `json:"price" validate:"required,gt=0"`
-	CreatedAt time.Time `json:"created_at"`
-}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Изменения профиля, которые записываются в profile_audit
+const (
+	ProfileAuditCreate  = "create"
+	ProfileAuditUpdate  = "update"
+	ProfileAuditDelete  = "delete"
+	ProfileAuditRestore = "restore"
+	ProfileAuditErase   = "erase"
+)
+
+// ProfileAuditRecord - запись журнала изменений профиля
+type ProfileAuditRecord struct {
+	ID        uuid.UUID       `json:"id"`
+	Action    string          `json:"action"`
+	ActorID   *uuid.UUID      `json:"actor_id,omitempty"` // кто изменил профиль: сам пользователь или администратор
+	RequestID string          `json:"request_id,omitempty"`
+	OldValues json.RawMessage `json:"old_values,omitempty" swaggertype:"object"`
+	NewValues json.RawMessage `json:"new_values,omitempty" swaggertype:"object"`
+	SourceIP  string          `json:"source_ip,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// ProfileHistoryResponse - журнал изменений профиля, от новых к старым
+type ProfileHistoryResponse struct {
+	Items []ProfileAuditRecord `json:"items"`
+}
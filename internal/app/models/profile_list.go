@@ -0,0 +1,113 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Поля, по которым можно сортировать список профилей
+const (
+	ProfileSortCreatedAt = "created_at"
+	ProfileSortLastName  = "last_name"
+)
+
+const (
+	DefaultProfileListLimit = 20
+	MaxProfileListLimit     = 100
+)
+
+// ProfileListFilter - фильтры, сортировка и курсор списка профилей
+type ProfileListFilter struct {
+	City        string    `form:"city" validate:"omitempty,max=100"`
+	NamePrefix  string    `form:"name" validate:"omitempty,max=50"` // префикс имени или фамилии, без учета регистра
+	CreatedFrom time.Time `form:"created_from"`                     // RFC3339, включительно
+	CreatedTo   time.Time `form:"created_to"`                       // RFC3339, не включительно
+	Sort        string    `form:"sort" validate:"omitempty,oneof=created_at last_name"`
+	Order       string    `form:"order" validate:"omitempty,oneof=asc desc"`
+	Limit       int       `form:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor      string    `form:"cursor"` // next_cursor из предыдущей страницы
+}
+
+func (f *ProfileListFilter) Validate() error {
+	return validate.Struct(f)
+}
+
+// SetDefaults - сортировка по дате создания от новых к старым, 20 профилей на страницу
+func (f *ProfileListFilter) SetDefaults() {
+	if f.Sort == "" {
+		f.Sort = ProfileSortCreatedAt
+	}
+	if f.Order == "" {
+		f.Order = "desc"
+	}
+	if f.Limit == 0 {
+		f.Limit = DefaultProfileListLimit
+	}
+}
+
+// ProfileList - страница списка профилей
+type ProfileList struct {
+	Items      []UserProfileOut `json:"items"`
+	NextCursor string           `json:"next_cursor,omitempty"` // пустой на последней странице
+}
+
+// ProfileCursor - позиция последнего профиля страницы: значение поля сортировки и id.
+// Курсор привязан к сортировке, с другой сортировкой он не принимается
+type ProfileCursor struct {
+	Sort      string    `json:"s"`
+	CreatedAt time.Time `json:"c,omitempty"`
+	LastName  string    `json:"l,omitempty"`
+	ID        uuid.UUID `json:"i"`
+}
+
+// Encode - курсор в виде непрозрачной строки для клиента
+func (c ProfileCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeProfileCursor - разбор курсора, полученного от клиента
+func DecodeProfileCursor(s string, sort string) (ProfileCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return ProfileCursor{}, err
+	}
+	var cursor ProfileCursor
+	if err = json.Unmarshal(data, &cursor); err != nil {
+		return ProfileCursor{}, err
+	}
+	if cursor.Sort != sort || cursor.ID == uuid.Nil {
+		return ProfileCursor{}, errors.New("cursor does not match sort")
+	}
+	return cursor, nil
+}
+
+const (
+	DefaultProfileSearchLimit = 20
+	MaxProfileSearchLimit     = 50
+)
+
+// ProfileSearchQuery - нечеткий поиск по имени, фамилии и городу
+type ProfileSearchQuery struct {
+	Q     string `form:"q" validate:"required,min=2,max=100"`
+	Limit int    `form:"limit" validate:"omitempty,min=1,max=50"`
+}
+
+func (q *ProfileSearchQuery) Validate() error {
+	return validate.Struct(q)
+}
+
+// ProfileSearchResult - найденный профиль и степень совпадения от 0 до 1
+type ProfileSearchResult struct {
+	UserProfileOut
+	Score float64 `json:"score"`
+}
+
+// ProfileSearchResponse - результаты поиска, лучшие совпадения первыми
+type ProfileSearchResponse struct {
+	Items []ProfileSearchResult `json:"items"`
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestProfileCursorRoundTrip(t *testing.T) {
+	id := uuid.New()
+	tests := []struct {
+		name   string
+		cursor ProfileCursor
+	}{
+		{
+			name: "created_at",
+			cursor: ProfileCursor{
+				Sort:      ProfileSortCreatedAt,
+				CreatedAt: time.Date(2024, 3, 1, 12, 30, 15, 123456000, time.UTC),
+				ID:        id,
+			},
+		},
+		{
+			name:   "last_name",
+			cursor: ProfileCursor{Sort: ProfileSortLastName, LastName: "Иванов", ID: id},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := tt.cursor.Encode()
+			if strings.ContainsAny(encoded, "+/=") {
+				t.Fatalf("cursor %q is not URL-safe", encoded)
+			}
+
+			got, err := DecodeProfileCursor(encoded, tt.cursor.Sort)
+			if err != nil {
+				t.Fatalf("DecodeProfileCursor: %v", err)
+			}
+			if got.Sort != tt.cursor.Sort || got.LastName != tt.cursor.LastName || got.ID != tt.cursor.ID ||
+				!got.CreatedAt.Equal(tt.cursor.CreatedAt) {
+				t.Fatalf("DecodeProfileCursor = %+v, want %+v", got, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeProfileCursorRejectsInvalid(t *testing.T) {
+	valid := ProfileCursor{Sort: ProfileSortLastName, LastName: "Петров", ID: uuid.New()}.Encode()
+
+	tests := []struct {
+		name   string
+		cursor string
+		sort   string
+	}{
+		{name: "other sort", cursor: valid, sort: ProfileSortCreatedAt},
+		{name: "not base64", cursor: "not a cursor!", sort: ProfileSortLastName},
+		{name: "not json", cursor: base64.RawURLEncoding.EncodeToString([]byte("garbage")), sort: ProfileSortLastName},
+		{name: "no id", cursor: ProfileCursor{Sort: ProfileSortLastName, LastName: "Петров"}.Encode(), sort: ProfileSortLastName},
+		{name: "empty", cursor: "", sort: ProfileSortLastName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeProfileCursor(tt.cursor, tt.sort); err == nil {
+				t.Fatalf("DecodeProfileCursor(%q, %q) accepted an invalid cursor", tt.cursor, tt.sort)
+			}
+		})
+	}
+}
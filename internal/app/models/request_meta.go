@@ -0,0 +1,29 @@
+package models
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type requestMetaKey struct{}
+
+// RequestMeta - кто и откуда выполняет запрос, нужно для журнала изменений
+type RequestMeta struct {
+	RequestID string
+	SourceIP  string
+	ActorID   uuid.UUID // заполняется после проверки токена
+}
+
+// WithRequestMeta - контекст с данными запроса
+func WithRequestMeta(ctx context.Context, meta *RequestMeta) context.Context {
+	return context.WithValue(ctx, requestMetaKey{}, meta)
+}
+
+// RequestMetaFromContext - данные запроса, для фоновых задач возвращается пустая структура
+func RequestMetaFromContext(ctx context.Context) *RequestMeta {
+	if meta, ok := ctx.Value(requestMetaKey{}).(*RequestMeta); ok {
+		return meta
+	}
+	return &RequestMeta{}
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TokenRevocationInput - запрос на отзыв токена по jti или всех токенов пользователя
+type TokenRevocationInput struct {
+	TokenID   string    `json:"jti" validate:"required_without=UserID,omitempty,max=255"`
+	ExpiresAt time.Time `json:"expires_at" validate:"required_with=TokenID"` // exp отзываемого токена
+	UserID    uuid.UUID `json:"user_id" validate:"required_without=TokenID"` // отозвать все выпущенные до текущего момента токены
+}
+
+func (t *TokenRevocationInput) Validate() error {
+	return validate.Struct(t)
+}
+
+// Revocations - снимок списка отзыва
+type Revocations struct {
+	Tokens map[string]time.Time    // jti -> exp токена
+	Users  map[uuid.UUID]time.Time // user_id -> токены, выпущенные раньше, отозваны
+}
+
+// IsRevoked - отозван ли токен с данными claims
+func (r Revocations) IsRevoked(claims AccessClaims) bool {
+	if claims.TokenID != "" {
+		if _, ok := r.Tokens[claims.TokenID]; ok {
+			return true
+		}
+	}
+	if before, ok := r.Users[claims.UserID]; ok {
+		// Токен без iat нельзя отличить от выпущенного до отзыва
+		return claims.IssuedAt.IsZero() || claims.IssuedAt.Before(before)
+	}
+	return false
+}
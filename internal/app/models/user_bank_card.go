@@ -5,20 +5,44 @@ import (
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+
+	"service-user/internal/app/utils"
 )
 
 var validateBankCard *validator.Validate
 
 func init() {
-	validate = validator.New()
+	validateBankCard = validator.New()
+	_ = validateBankCard.RegisterValidation("luhn", validateLuhn)
+	_ = validateBankCard.RegisterValidation("card_brand", validateCardBrand)
+	_ = validateBankCard.RegisterValidation("future_expiry", validateFutureExpiry)
+}
+
+// validateLuhn - номер карты проходит проверку контрольной суммы
+func validateLuhn(fl validator.FieldLevel) bool {
+	return utils.LuhnValid(fl.Field().String())
+}
+
+// validateCardBrand - номер карты принадлежит известной платежной системе
+func validateCardBrand(fl validator.FieldLevel) bool {
+	return utils.CardBrand(fl.Field().String()) != utils.BrandUnknown
+}
+
+// validateFutureExpiry - срок действия в формате MM/YY еще не истек
+func validateFutureExpiry(fl validator.FieldLevel) bool {
+	expiresAt, err := utils.CardExpiresAt(fl.Field().String())
+	if err != nil {
+		return false
+	}
+	return time.Now().Before(expiresAt)
 }
 
 // UserBankCard представляет банковскую карту пользователя
 type UserBankCard struct {
 	ID             uuid.UUID `json:"id"`
-	UserProfileID  uuid.UUID `json:"-" validate:"required"`
-	CardNumber     string    `json:"card_number" validate:"required,len=16,numeric"`
-	ExpirationDate string    `json:"expiration_date" validate:"required,len=5,datetime=02/06"` // MM/YY
+	UserProfileID  uuid.UUID `json:"-"`
+	CardNumber     string    `json:"card_number" validate:"required,min=13,max=19,numeric,luhn,card_brand"`
+	ExpirationDate string    `json:"expiration_date" validate:"required,len=5,future_expiry"` // MM/YY
 	CardHolderName string    `json:"card_holder_name" validate:"required,min=2,max=100"`
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
@@ -27,3 +51,62 @@ type UserBankCard struct {
 func (u *UserBankCard) Validate() error {
 	return validateBankCard.Struct(u)
 }
+
+// UserBankCardUpdate - частичное обновление карты, номер карты не меняется
+type UserBankCardUpdate struct {
+	ExpirationDate string `json:"expiration_date" validate:"omitempty,len=5,future_expiry"` // MM/YY
+	CardHolderName string `json:"card_holder_name" validate:"omitempty,min=2,max=100"`
+}
+
+func (u *UserBankCardUpdate) Validate() error {
+	return validateBankCard.Struct(u)
+}
+
+// UserBankCardOut - карта в ответе API, номер карты всегда маскирован
+type UserBankCardOut struct {
+	ID             uuid.UUID `json:"id"`
+	Token          uuid.UUID `json:"token"` // токен карты в хранилище, по нему карта передается в оплату
+	CardNumber     string    `json:"card_number" example:"**** **** **** 1234"`
+	Brand          string    `json:"brand" example:"Visa"`
+	ExpirationDate string    `json:"expiration_date"`
+	CardHolderName string    `json:"card_holder_name"`
+	IsDefault      bool      `json:"is_default"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ExpiringCard - карта, срок действия которой скоро истекает
+type ExpiringCard struct {
+	CardID         uuid.UUID `json:"card_id"`
+	CardToken      uuid.UUID `json:"card_token"`
+	UserID         uuid.UUID `json:"user_id"`
+	UserProfileID  uuid.UUID `json:"user_profile_id"`
+	CardNumber     string    `json:"card_number"` // маскированный номер
+	Brand          string    `json:"brand"`
+	ExpirationDate string    `json:"expiration_date"`
+}
+
+// CardFingerprint - открытые признаки карты, по которым ее можно показать пользователю
+type CardFingerprint struct {
+	Last4          string `json:"last4"`
+	Brand          string `json:"brand"`
+	ExpirationDate string `json:"expiration_date"`
+}
+
+// CardToken - непрозрачный токен, выдаваемый хранилищем карт вместо номера
+type CardToken struct {
+	Token       uuid.UUID       `json:"token"`
+	Fingerprint CardFingerprint `json:"fingerprint"`
+}
+
+type BankCardIdResponse struct {
+	ID uuid.UUID `json:"id"`
+}
+
+// MaskCardNumber - оставляет от номера карты только последние 4 цифры
+func MaskCardNumber(number string) string {
+	if len(number) < 4 {
+		return "**** **** **** ****"
+	}
+	return "**** **** **** " + number[len(number)-4:]
+}
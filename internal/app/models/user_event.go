@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Типы событий сервиса авторизации
+const (
+	UserRegistered = "user.registered"
+	UserDeleted    = "user.deleted"
+)
+
+// UserEventSchemaVersion - поддерживаемая версия конверта событий сервиса авторизации
+const UserEventSchemaVersion = 1
+
+// UserLifecycleEvent - событие сервиса авторизации о регистрации или удалении пользователя
+type UserLifecycleEvent struct {
+	SchemaVersion int                  `json:"schema_version" validate:"eq=1"`
+	ID            uuid.UUID            `json:"id" validate:"required"`
+	Type          string               `json:"type" validate:"required"`
+	OccurredAt    time.Time            `json:"occurred_at"`
+	Payload       UserLifecyclePayload `json:"payload"`
+}
+
+// UserLifecyclePayload - данные события, имя передается только в user.registered и необязательно
+type UserLifecyclePayload struct {
+	UserID    uuid.UUID `json:"user_id" validate:"required"`
+	FirstName string    `json:"first_name" validate:"omitempty,max=50"`
+	LastName  string    `json:"last_name" validate:"omitempty,max=50"`
+}
+
+func (e *UserLifecycleEvent) Validate() error {
+	return validate.Struct(e)
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	logger "github.com/sirupsen/logrus"
+
+	"service-user/internal/app/errs"
+	"service-user/internal/app/models"
+)
+
+// AdminRepos - репозиторий действий администратора с чужими профилями.
+// Каждое действие записывается в admin_actions в одной транзакции с самим действием
+type AdminRepos struct {
+	db *pgxpool.Pool
+}
+
+// NewAdminRepository - конструктор репозитория действий администратора
+func NewAdminRepository(db *pgxpool.Pool) *AdminRepos {
+	return &AdminRepos{db: db}
+}
+
+// AdminGetProfile - получение профиля по id профиля или user_id
+func (r *AdminRepos) AdminGetProfile(ctx context.Context, adminID uuid.UUID, ref models.ProfileRef) (models.UserProfileOut, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Errorf("Error while starting transaction %v", err)
+		return models.UserProfileOut{}, errs.ErrProfileNotFound
+	}
+	defer tx.Rollback(ctx)
+
+	condition, arg := profileRefCondition(ref, 1)
+	query := `SELECT id, user_id, first_name, last_name, city, created_at, updated_at FROM user_profiles WHERE ` + condition
+
+	var profile models.UserProfileOut
+	err = tx.QueryRow(ctx, query, arg).Scan(&profile.ID, &profile.UserID, &profile.FirstName, &profile.LastName,
+		&profile.City, &profile.CreatedAt, &profile.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.UserProfileOut{}, errs.ErrProfileNotFound
+		}
+		logger.Errorf("Error while getting user-profile %v", err)
+		return models.UserProfileOut{}, errs.ErrProfileNotFound
+	}
+
+	err = recordAdminAction(ctx, tx, adminID, models.AdminActionViewProfile, profile.ID, profile.UserID, nil)
+	if err != nil {
+		return models.UserProfileOut{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		logger.Errorf("Error while committing admin action %v", err)
+		return models.UserProfileOut{}, errs.ErrAdminAction
+	}
+	return profile, nil
+}
+
+// AdminUpdateProfile - частичное обновление профиля по id профиля или user_id
+func (r *AdminRepos) AdminUpdateProfile(ctx context.Context, adminID uuid.UUID, ref models.ProfileRef, profile models.UserProfileUpdate) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Errorf("Error while starting transaction %v", err)
+		return errs.ErrUpdateUserProfile
+	}
+	defer tx.Rollback(ctx)
+
+	condition, arg := profileRefCondition(ref, 1)
+	updated, err := updateProfileTx(ctx, tx, condition, arg, profile)
+	if err != nil {
+		return err
+	}
+
+	err = recordAdminAction(ctx, tx, adminID, models.AdminActionUpdateProfile, updated.ID, updated.UserID, profile)
+	if err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		logger.Errorf("Error while committing admin action %v", err)
+		return errs.ErrUpdateUserProfile
+	}
+	logger.Infof("Admin %v updated user-profile %v", adminID, updated.ID)
+	return nil
+}
+
+// AdminDeleteProfile - удаление профиля по id профиля или user_id, как и у пользователя профиль можно восстановить
+func (r *AdminRepos) AdminDeleteProfile(ctx context.Context, adminID uuid.UUID, ref models.ProfileRef) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Errorf("Error while starting transaction %v", err)
+		return errs.ErrDeleteUserProfile
+	}
+	defer tx.Rollback(ctx)
+
+	// Профиль по id сначала блокируется, чтобы удалить именно его, а не профиль, созданный заново
+	condition, arg := profileRefCondition(ref, 1)
+	query := `SELECT user_id FROM user_profiles WHERE ` + condition + ` FOR UPDATE`
+
+	var userID uuid.UUID
+	err = tx.QueryRow(ctx, query, arg).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errs.ErrProfileNotFound
+		}
+		logger.Errorf("Error while deleting user-profile %v", err)
+		return errs.ErrDeleteUserProfile
+	}
+
+	profileID, err := deleteProfileTx(ctx, tx, userID, models.ProfileVersion{Version: models.AnyVersion})
+	if err != nil {
+		return err
+	}
+	err = recordAdminAction(ctx, tx, adminID, models.AdminActionDeleteProfile, profileID, userID, nil)
+	if err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		logger.Errorf("Error while committing admin action %v", err)
+		return errs.ErrDeleteUserProfile
+	}
+	logger.Infof("Admin %v deleted user-profile %v", adminID, profileID)
+	return nil
+}
+
+// profileRefCondition - условие WHERE для ссылки на профиль с плейсхолдером $argID.
+// Незаполненные профили, созданные по событию регистрации, администратору не видны
+func profileRefCondition(ref models.ProfileRef, argID int) (string, uuid.UUID) {
+	if ref.ID != uuid.Nil {
+		return fmt.Sprintf("id = $%d AND deleted_at IS NULL AND NOT skeleton", argID), ref.ID
+	}
+	return fmt.Sprintf("user_id = $%d AND deleted_at IS NULL AND NOT skeleton", argID), ref.UserID
+}
+
+// recordAdminAction - запись действия администратора, details сохраняются как JSON
+func recordAdminAction(ctx context.Context, tx pgx.Tx, adminID uuid.UUID, action string, profileID uuid.UUID, userID uuid.UUID, details interface{}) error {
+	var detailsJSON []byte
+	if details != nil {
+		var err error
+		detailsJSON, err = json.Marshal(details)
+		if err != nil {
+			logger.Errorf("Error while encoding admin action details %v", err)
+			return errs.ErrAdminAction
+		}
+	}
+
+	query := `
+		INSERT INTO admin_actions (admin_id, action, profile_id, user_id, details)
+		VALUES ($1, $2, $3, $4, $5)`
+	_, err := tx.Exec(ctx, query, adminID, action, profileID, userID, detailsJSON)
+	if err != nil {
+		logger.Errorf("Error while recording admin action %v", err)
+		return errs.ErrAdminAction
+	}
+	return nil
+}
+
+// GetProfileAdminActions - действия администраторов с профилем пользователя, от старых к новым
+func (r *AdminRepos) GetProfileAdminActions(ctx context.Context, userID uuid.UUID) ([]models.AuditRecord, error) {
+	return getProfileAdminActions(ctx, r.db, userID)
+}
+
+func getProfileAdminActions(ctx context.Context, q querier, userID uuid.UUID) ([]models.AuditRecord, error) {
+	query := `
+		SELECT action, details, created_at
+		FROM admin_actions
+		WHERE user_id = $1
+		ORDER BY created_at`
+	rows, err := q.Query(ctx, query, userID)
+	if err != nil {
+		logger.Errorf("Error while getting admin actions %v", err)
+		return nil, errs.ErrGetAuditHistory
+	}
+	defer rows.Close()
+
+	records := []models.AuditRecord{}
+	for rows.Next() {
+		var record models.AuditRecord
+		var details []byte
+		if err = rows.Scan(&record.Action, &details, &record.CreatedAt); err != nil {
+			logger.Errorf("Error while scanning admin action %v", err)
+			return nil, errs.ErrGetAuditHistory
+		}
+		record.Details = details
+		records = append(records, record)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Errorf("Error while reading admin actions %v", err)
+		return nil, errs.ErrGetAuditHistory
+	}
+	return records, nil
+}
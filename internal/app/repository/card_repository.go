@@ -0,0 +1,349 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	logger "github.com/sirupsen/logrus"
+
+	"service-user/internal/app/errs"
+	"service-user/internal/app/events"
+	"service-user/internal/app/models"
+	"service-user/internal/app/vault"
+)
+
+// CardRepos - репозиторий банковских карт пользователя, номера карт хранятся в vault.Vault
+type CardRepos struct {
+	db    *pgxpool.Pool
+	vault vault.Vault
+}
+
+// NewCardRepository - конструктор репозитория банковских карт
+func NewCardRepository(db *pgxpool.Pool, cardVault vault.Vault) *CardRepos {
+	return &CardRepos{db: db, vault: cardVault}
+}
+
+// CreateCard - добавление карты в профиль пользователя, номер карты обменивается на токен в той же транзакции
+func (r *CardRepos) CreateCard(ctx context.Context, userID uuid.UUID, card models.UserBankCard) (uuid.UUID, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Errorf("Error while starting transaction %v", err)
+		return uuid.UUID{}, errs.ErrCreateCard
+	}
+	defer tx.Rollback(ctx)
+
+	// Блокировка профиля упорядочивает одновременное добавление карт, иначе обе первые карты
+	// станут картами по умолчанию. NO KEY UPDATE не мешает хранилищу ссылаться на профиль
+	var profileID uuid.UUID
+	query := `SELECT id FROM user_profiles WHERE user_id = $1 AND deleted_at IS NULL FOR NO KEY UPDATE`
+	err = tx.QueryRow(ctx, query, userID).Scan(&profileID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.UUID{}, errs.ErrProfileNotFound
+		}
+		logger.Errorf("Error while getting user-profile %v", err)
+		return uuid.UUID{}, errs.ErrCreateCard
+	}
+
+	token, err := r.vault.TokenizeTx(ctx, tx, profileID, card.CardNumber, card.ExpirationDate)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+
+	// Первая карта профиля становится картой по умолчанию
+	query = `
+		INSERT INTO user_bank_cards (user_profile_id, card_token, expiration_date, card_holder_name, is_default)
+		VALUES ($1, $2, $3, $4, NOT EXISTS (SELECT 1 FROM user_bank_cards WHERE user_profile_id = $1))
+		RETURNING id`
+	var id uuid.UUID
+	err = tx.QueryRow(ctx, query, profileID, token.Token, card.ExpirationDate, card.CardHolderName).Scan(&id)
+	if err != nil {
+		logger.Errorf("Error while inserting bank card %v", err)
+		return uuid.UUID{}, errs.ErrCreateCard
+	}
+	if err = tx.Commit(ctx); err != nil {
+		logger.Errorf("Error while committing bank card %v", err)
+		return uuid.UUID{}, errs.ErrCreateCard
+	}
+	logger.Infof("Created bank card %v", id)
+	return id, nil
+}
+
+// GetCards - все карты пользователя
+func (r *CardRepos) GetCards(ctx context.Context, userID uuid.UUID) ([]models.UserBankCardOut, error) {
+	return getCards(ctx, r.db, userID)
+}
+
+func getCards(ctx context.Context, q querier, userID uuid.UUID) ([]models.UserBankCardOut, error) {
+	query := `
+		SELECT c.id, c.card_token, v.card_last4, COALESCE(v.brand, 'Unknown'), c.expiration_date, c.card_holder_name, c.is_default, c.created_at, c.updated_at
+		FROM user_bank_cards c
+		JOIN user_profiles p ON p.id = c.user_profile_id
+		JOIN card_vault v ON v.token = c.card_token
+		WHERE p.user_id = $1 AND p.deleted_at IS NULL
+		ORDER BY c.created_at`
+	rows, err := q.Query(ctx, query, userID)
+	if err != nil {
+		logger.Errorf("Error while getting bank cards %v", err)
+		return nil, errs.ErrGetCard
+	}
+	defer rows.Close()
+
+	cards := []models.UserBankCardOut{}
+	for rows.Next() {
+		card, err := scanCard(rows)
+		if err != nil {
+			logger.Errorf("Error while scanning bank card %v", err)
+			return nil, errs.ErrGetCard
+		}
+		cards = append(cards, card)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Errorf("Error while reading bank cards %v", err)
+		return nil, errs.ErrGetCard
+	}
+	return cards, nil
+}
+
+// GetCard - карта пользователя по id
+func (r *CardRepos) GetCard(ctx context.Context, userID uuid.UUID, cardID uuid.UUID) (models.UserBankCardOut, error) {
+	query := `
+		SELECT c.id, c.card_token, v.card_last4, COALESCE(v.brand, 'Unknown'), c.expiration_date, c.card_holder_name, c.is_default, c.created_at, c.updated_at
+		FROM user_bank_cards c
+		JOIN user_profiles p ON p.id = c.user_profile_id
+		JOIN card_vault v ON v.token = c.card_token
+		WHERE p.user_id = $1 AND c.id = $2 AND p.deleted_at IS NULL`
+	card, err := scanCard(r.db.QueryRow(ctx, query, userID, cardID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.UserBankCardOut{}, errs.ErrCardNotFound
+		}
+		logger.Errorf("Error while getting bank card %v", err)
+		return models.UserBankCardOut{}, errs.ErrGetCard
+	}
+	return card, nil
+}
+
+// UpdateCard - частичное обновление карты пользователя
+func (r *CardRepos) UpdateCard(ctx context.Context, userID uuid.UUID, cardID uuid.UUID, card models.UserBankCardUpdate) error {
+	var updates []string
+	var args []interface{}
+	argID := 1
+
+	if card.ExpirationDate != "" {
+		// о новом сроке действия нужно будет уведомить заново
+		updates = append(updates, fmt.Sprintf("expiration_date = $%d", argID), "expiry_notified_at = NULL")
+		args = append(args, card.ExpirationDate)
+		argID++
+	}
+	if card.CardHolderName != "" {
+		updates = append(updates, fmt.Sprintf("card_holder_name = $%d", argID))
+		args = append(args, card.CardHolderName)
+		argID++
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	args = append(args, cardID, userID)
+	query := fmt.Sprintf(`
+		UPDATE user_bank_cards c
+		SET %s
+		FROM user_profiles p
+		WHERE p.id = c.user_profile_id AND c.id = $%d AND p.user_id = $%d AND p.deleted_at IS NULL`, strings.Join(updates, ", "), argID, argID+1)
+
+	tag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		logger.Errorf("Error while updating bank card %v", err)
+		return errs.ErrUpdateCard
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.ErrCardNotFound
+	}
+	return nil
+}
+
+// DeleteCard - удаление карты пользователя вместе с номером в хранилище.
+// Если удаляется карта по умолчанию, ею становится последняя добавленная карта
+func (r *CardRepos) DeleteCard(ctx context.Context, userID uuid.UUID, cardID uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Errorf("Error while deleting bank card %v", err)
+		return errs.ErrDeleteCard
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		DELETE FROM user_bank_cards c
+		USING user_profiles p
+		WHERE p.id = c.user_profile_id AND c.id = $1 AND p.user_id = $2 AND p.deleted_at IS NULL
+		RETURNING c.card_token, c.user_profile_id, c.is_default`
+	var token, profileID uuid.UUID
+	var wasDefault bool
+	err = tx.QueryRow(ctx, query, cardID, userID).Scan(&token, &profileID, &wasDefault)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errs.ErrCardNotFound
+		}
+		logger.Errorf("Error while deleting bank card %v", err)
+		return errs.ErrDeleteCard
+	}
+
+	if wasDefault {
+		query = `
+			UPDATE user_bank_cards
+			SET is_default = TRUE
+			WHERE id = (
+				SELECT id FROM user_bank_cards
+				WHERE user_profile_id = $1
+				ORDER BY created_at DESC
+				LIMIT 1
+			)`
+		if _, err = tx.Exec(ctx, query, profileID); err != nil {
+			logger.Errorf("Error while promoting default bank card %v", err)
+			return errs.ErrDeleteCard
+		}
+	}
+
+	if err = r.vault.DeleteTx(ctx, tx, token); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		logger.Errorf("Error while deleting bank card %v", err)
+		return errs.ErrDeleteCard
+	}
+	return nil
+}
+
+// SetDefaultCard - делает карту картой по умолчанию, снимая признак с предыдущей
+func (r *CardRepos) SetDefaultCard(ctx context.Context, userID uuid.UUID, cardID uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Errorf("Error while setting default bank card %v", err)
+		return errs.ErrUpdateCard
+	}
+	defer tx.Rollback(ctx)
+
+	var profileID uuid.UUID
+	query := `
+		SELECT c.user_profile_id
+		FROM user_bank_cards c
+		JOIN user_profiles p ON p.id = c.user_profile_id
+		WHERE c.id = $1 AND p.user_id = $2 AND p.deleted_at IS NULL
+		FOR UPDATE OF c`
+	err = tx.QueryRow(ctx, query, cardID, userID).Scan(&profileID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errs.ErrCardNotFound
+		}
+		logger.Errorf("Error while setting default bank card %v", err)
+		return errs.ErrUpdateCard
+	}
+
+	// Уникальный индекс проверяется построчно, поэтому сначала снимаем старый признак
+	query = `UPDATE user_bank_cards SET is_default = FALSE WHERE user_profile_id = $1 AND is_default AND id <> $2`
+	if _, err = tx.Exec(ctx, query, profileID, cardID); err != nil {
+		logger.Errorf("Error while resetting default bank card %v", err)
+		return errs.ErrUpdateCard
+	}
+
+	query = `UPDATE user_bank_cards SET is_default = TRUE WHERE id = $1 AND NOT is_default`
+	if _, err = tx.Exec(ctx, query, cardID); err != nil {
+		logger.Errorf("Error while setting default bank card %v", err)
+		return errs.ErrUpdateCard
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		logger.Errorf("Error while setting default bank card %v", err)
+		return errs.ErrUpdateCard
+	}
+	return nil
+}
+
+// NotifyExpiringCards - записывает в outbox событие card.expiring по картам, срок действия которых истекает
+// в ближайшие within и о которых еще не уведомляли, и отмечает их в той же транзакции. Карты, которые
+// сейчас обрабатывает другой экземпляр сервиса, пропускаются. Возвращает число обработанных карт
+func (r *CardRepos) NotifyExpiringCards(ctx context.Context, within time.Duration, limit int) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Errorf("Error while starting transaction %v", err)
+		return 0, errs.ErrUpdateCard
+	}
+	defer tx.Rollback(ctx)
+
+	// MM/YY действует до конца месяца включительно
+	query := `
+		SELECT c.id, c.card_token, p.user_id, p.id, v.card_last4, COALESCE(v.brand, 'Unknown'), c.expiration_date
+		FROM user_bank_cards c
+		JOIN user_profiles p ON p.id = c.user_profile_id
+		JOIN card_vault v ON v.token = c.card_token
+		WHERE c.expiry_notified_at IS NULL
+		  AND p.deleted_at IS NULL
+		  AND to_date(c.expiration_date, 'MM/YY') + INTERVAL '1 month' > NOW()
+		  AND to_date(c.expiration_date, 'MM/YY') + INTERVAL '1 month' <= NOW() + make_interval(secs => $1)
+		ORDER BY c.id
+		LIMIT $2
+		FOR UPDATE OF c SKIP LOCKED`
+	rows, err := tx.Query(ctx, query, within.Seconds(), limit)
+	if err != nil {
+		logger.Errorf("Error while getting expiring bank cards %v", err)
+		return 0, errs.ErrGetCard
+	}
+
+	var cards []models.ExpiringCard
+	for rows.Next() {
+		var card models.ExpiringCard
+		var last4 string
+		err = rows.Scan(&card.CardID, &card.CardToken, &card.UserID, &card.UserProfileID, &last4, &card.Brand, &card.ExpirationDate)
+		if err != nil {
+			rows.Close()
+			logger.Errorf("Error while scanning expiring bank card %v", err)
+			return 0, errs.ErrGetCard
+		}
+		card.CardNumber = models.MaskCardNumber(last4)
+		cards = append(cards, card)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		logger.Errorf("Error while reading expiring bank cards %v", err)
+		return 0, errs.ErrGetCard
+	}
+
+	cardIDs := make([]uuid.UUID, 0, len(cards))
+	for _, card := range cards {
+		if err = recordOutboxEvent(ctx, tx, events.CardExpiring, card.CardID, card.UserID, card); err != nil {
+			return 0, err
+		}
+		cardIDs = append(cardIDs, card.CardID)
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE user_bank_cards SET expiry_notified_at = NOW() WHERE id = ANY($1)`, cardIDs)
+	if err != nil {
+		logger.Errorf("Error while marking bank card expiry notified %v", err)
+		return 0, errs.ErrUpdateCard
+	}
+	if err = tx.Commit(ctx); err != nil {
+		logger.Errorf("Error while committing bank card expiry notifications %v", err)
+		return 0, errs.ErrUpdateCard
+	}
+	return len(cards), nil
+}
+
+// scanCard - читает строку карты, полный номер наружу не отдается
+func scanCard(row pgx.Row) (models.UserBankCardOut, error) {
+	var card models.UserBankCardOut
+	var last4 string
+	err := row.Scan(&card.ID, &card.Token, &last4, &card.Brand, &card.ExpirationDate, &card.CardHolderName, &card.IsDefault, &card.CreatedAt, &card.UpdatedAt)
+	if err != nil {
+		return models.UserBankCardOut{}, err
+	}
+	card.CardNumber = models.MaskCardNumber(last4)
+	return card, nil
+}
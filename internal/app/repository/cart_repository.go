@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	logger "github.com/sirupsen/logrus"
+
+	"service-user/internal/app/errs"
+	"service-user/internal/app/events"
+	"service-user/internal/app/models"
+)
+
+// CartRepos - репозиторий корзины пользователя
+type CartRepos struct {
+	db *pgxpool.Pool
+}
+
+// NewCartRepository - конструктор репозитория корзины
+func NewCartRepository(db *pgxpool.Pool) *CartRepos {
+	return &CartRepos{db: db}
+}
+
+// ensureCart - возвращает id корзины пользователя, создавая ее при первом добавлении товара.
+// DO UPDATE вместо DO NOTHING возвращает строку и тогда, когда корзину одновременно создала другая транзакция
+func ensureCart(ctx context.Context, tx pgx.Tx, userID uuid.UUID) (uuid.UUID, error) {
+	query := `
+		INSERT INTO cart (user_profile_id)
+		SELECT id FROM user_profiles WHERE user_id = $1 AND deleted_at IS NULL
+		ON CONFLICT (user_profile_id) DO UPDATE SET user_profile_id = EXCLUDED.user_profile_id
+		RETURNING id`
+
+	var id uuid.UUID
+	err := tx.QueryRow(ctx, query, userID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.UUID{}, errs.ErrProfileNotFound
+		}
+		logger.Errorf("Error while getting cart %v", err)
+		return uuid.UUID{}, errs.ErrGetCart
+	}
+	return id, nil
+}
+
+// GetCart - получение корзины пользователя вместе с товарами, корзина при этом не создается
+func (r *CartRepos) GetCart(ctx context.Context, userID uuid.UUID) (models.CartOut, error) {
+	return getCart(ctx, r.db, userID)
+}
+
+// getCart - корзина пользователя. Пока товар не добавлен, корзины нет, возвращается пустая корзина без id
+func getCart(ctx context.Context, q querier, userID uuid.UUID) (models.CartOut, error) {
+	var cart models.CartOut
+	var id *uuid.UUID
+	var createdAt, updatedAt *time.Time
+	query := `
+		SELECT p.id, c.id, c.created_at, c.updated_at
+		FROM user_profiles p
+		LEFT JOIN cart c ON c.user_profile_id = p.id
+		WHERE p.user_id = $1 AND p.deleted_at IS NULL`
+	err := q.QueryRow(ctx, query, userID).Scan(&cart.UserProfileID, &id, &createdAt, &updatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.CartOut{}, errs.ErrProfileNotFound
+		}
+		logger.Errorf("Error while getting cart %v", err)
+		return models.CartOut{}, errs.ErrGetCart
+	}
+
+	cart.Items = []models.CartItem{}
+	if id == nil {
+		return cart, nil
+	}
+	cart.ID, cart.CreatedAt, cart.UpdatedAt = *id, *createdAt, *updatedAt
+
+	query = `
+		SELECT id, cart_id, product_id, quantity, price, created_at, updated_at
+		FROM cart_items
+		WHERE cart_id = $1
+		ORDER BY created_at`
+	rows, err := q.Query(ctx, query, cart.ID)
+	if err != nil {
+		logger.Errorf("Error while getting cart items %v", err)
+		return models.CartOut{}, errs.ErrGetCart
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.CartItem
+		err = rows.Scan(&item.ID, &item.CartID, &item.ProductID, &item.Quantity, &item.Price, &item.CreatedAt, &item.UpdatedAt)
+		if err != nil {
+			logger.Errorf("Error while scanning cart item %v", err)
+			return models.CartOut{}, errs.ErrGetCart
+		}
+		cart.Items = append(cart.Items, item)
+		cart.TotalPrice += item.Price * float64(item.Quantity)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Errorf("Error while reading cart items %v", err)
+		return models.CartOut{}, errs.ErrGetCart
+	}
+	return cart, nil
+}
+
+// AddItem - добавление товара по цене из каталога, повторное добавление увеличивает количество
+func (r *CartRepos) AddItem(ctx context.Context, userID uuid.UUID, item models.CartItemInput, price float64) (uuid.UUID, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Errorf("Error while starting transaction %v", err)
+		return uuid.UUID{}, errs.ErrAddCartItem
+	}
+	defer tx.Rollback(ctx)
+
+	cartID, err := ensureCart(ctx, tx, userID)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+
+	query := `
+		INSERT INTO cart_items (cart_id, product_id, quantity, price)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (cart_id, product_id) DO UPDATE
+		SET quantity = cart_items.quantity + EXCLUDED.quantity,
+		    price = EXCLUDED.price
+		RETURNING id, quantity`
+	var id uuid.UUID
+	var quantity int
+	err = tx.QueryRow(ctx, query, cartID, item.ProductID, item.Quantity, price).Scan(&id, &quantity)
+	if err != nil {
+		logger.Errorf("Error while adding cart item %v", err)
+		return uuid.UUID{}, errs.ErrAddCartItem
+	}
+
+	// В событии итоговое количество товара в корзине
+	err = recordOutboxEvent(ctx, tx, events.CartItemAdded, cartID, userID, events.CartPayload{
+		UserID:    userID,
+		CartID:    cartID,
+		ItemID:    &id,
+		ProductID: &item.ProductID,
+		Quantity:  quantity,
+		Price:     price,
+	})
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		logger.Errorf("Error while committing cart item %v", err)
+		return uuid.UUID{}, errs.ErrAddCartItem
+	}
+	logger.Infof("Added item %v to cart %v", id, cartID)
+	return id, nil
+}
+
+// UpdateItemQuantity - изменение количества товара в корзине
+func (r *CartRepos) UpdateItemQuantity(ctx context.Context, userID uuid.UUID, itemID uuid.UUID, quantity int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Errorf("Error while starting transaction %v", err)
+		return errs.ErrUpdateCartItem
+	}
+	defer tx.Rollback(ctx)
+
+	var productID uuid.UUID
+	payload := events.CartPayload{UserID: userID, ItemID: &itemID, ProductID: &productID, Quantity: quantity}
+	query := `
+		UPDATE cart_items ci
+		SET quantity = $1
+		FROM cart c
+		JOIN user_profiles p ON p.id = c.user_profile_id
+		WHERE ci.id = $2 AND ci.cart_id = c.id AND p.user_id = $3 AND p.deleted_at IS NULL
+		RETURNING ci.cart_id, ci.product_id, ci.price`
+	err = tx.QueryRow(ctx, query, quantity, itemID, userID).Scan(&payload.CartID, &productID, &payload.Price)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errs.ErrCartItemNotFound
+		}
+		logger.Errorf("Error while updating cart item %v", err)
+		return errs.ErrUpdateCartItem
+	}
+
+	if err = recordOutboxEvent(ctx, tx, events.CartItemUpdated, payload.CartID, userID, payload); err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		logger.Errorf("Error while committing cart item %v", err)
+		return errs.ErrUpdateCartItem
+	}
+	return nil
+}
+
+// RemoveItem - удаление товара из корзины
+func (r *CartRepos) RemoveItem(ctx context.Context, userID uuid.UUID, itemID uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Errorf("Error while starting transaction %v", err)
+		return errs.ErrDeleteCartItem
+	}
+	defer tx.Rollback(ctx)
+
+	var productID uuid.UUID
+	payload := events.CartPayload{UserID: userID, ItemID: &itemID, ProductID: &productID}
+	query := `
+		DELETE FROM cart_items ci
+		USING cart c, user_profiles p
+		WHERE ci.id = $1 AND ci.cart_id = c.id AND c.user_profile_id = p.id AND p.user_id = $2 AND p.deleted_at IS NULL
+		RETURNING ci.cart_id, ci.product_id`
+	err = tx.QueryRow(ctx, query, itemID, userID).Scan(&payload.CartID, &productID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errs.ErrCartItemNotFound
+		}
+		logger.Errorf("Error while deleting cart item %v", err)
+		return errs.ErrDeleteCartItem
+	}
+
+	if err = recordOutboxEvent(ctx, tx, events.CartItemRemoved, payload.CartID, userID, payload); err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		logger.Errorf("Error while committing cart item deletion %v", err)
+		return errs.ErrDeleteCartItem
+	}
+	return nil
+}
+
+// ClearCart - удаление всех товаров из корзины
+func (r *CartRepos) ClearCart(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Errorf("Error while starting transaction %v", err)
+		return errs.ErrDeleteCartItem
+	}
+	defer tx.Rollback(ctx)
+
+	// Все товары лежат в одной корзине, поэтому cart_id не больше одного, строки нет без профиля
+	query := `
+		WITH deleted AS (
+			DELETE FROM cart_items ci
+			USING cart c, user_profiles p
+			WHERE ci.cart_id = c.id AND c.user_profile_id = p.id AND p.user_id = $1 AND p.deleted_at IS NULL
+			RETURNING ci.cart_id
+		)
+		SELECT (SELECT cart_id FROM deleted LIMIT 1)
+		FROM user_profiles
+		WHERE user_id = $1 AND deleted_at IS NULL`
+	var deletedCartID *uuid.UUID
+	err = tx.QueryRow(ctx, query, userID).Scan(&deletedCartID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errs.ErrProfileNotFound
+		}
+		logger.Errorf("Error while clearing cart %v", err)
+		return errs.ErrDeleteCartItem
+	}
+	// Корзина уже пуста, событие не нужно
+	if deletedCartID == nil {
+		return nil
+	}
+	cartID := *deletedCartID
+
+	err = recordOutboxEvent(ctx, tx, events.CartCleared, cartID, userID, events.CartPayload{UserID: userID, CartID: cartID})
+	if err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		logger.Errorf("Error while committing cart clearing %v", err)
+		return errs.ErrDeleteCartItem
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	logger "github.com/sirupsen/logrus"
+
+	"service-user/internal/app/errs"
+	"service-user/internal/app/events"
+	"service-user/internal/app/models"
+)
+
+// ErasureRepos - репозиторий стирания персональных данных
+type ErasureRepos struct {
+	db           *pgxpool.Pool
+	tombstoneKey []byte
+}
+
+// NewErasureRepository - конструктор репозитория стирания персональных данных,
+// tombstoneKey - ключ HMAC для отметок о стертых пользователях
+func NewErasureRepository(db *pgxpool.Pool, tombstoneKey []byte) *ErasureRepos {
+	return &ErasureRepos{db: db, tombstoneKey: tombstoneKey}
+}
+
+// EraseProfile - стирание персональных данных профиля. Имя, фамилия и город заменяются случайными
+// псевдонимами, user_id - случайным uuid, карты и сохраненные ответы на запросы пользователя удаляются,
+// корзина остается за обезличенным профилем.
+// Если adminID задан, действие записывается в журнал администратора. Возвращает id профиля
+func (r *ErasureRepos) EraseProfile(ctx context.Context, ref models.ProfileRef, adminID uuid.UUID) (uuid.UUID, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Errorf("Error while starting transaction %v", err)
+		return uuid.UUID{}, errs.ErrEraseProfile
+	}
+	defer tx.Rollback(ctx)
+
+	// Стереть можно и удаленный профиль, пока он не удален окончательно
+	condition := "user_id = $1"
+	arg := ref.UserID
+	if ref.ID != uuid.Nil {
+		condition, arg = "id = $1", ref.ID
+	}
+	var profileID, userID uuid.UUID
+	query := `SELECT id, user_id FROM user_profiles WHERE ` + condition + ` AND erased_at IS NULL FOR UPDATE`
+	err = tx.QueryRow(ctx, query, arg).Scan(&profileID, &userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.UUID{}, errs.ErrProfileNotFound
+		}
+		logger.Errorf("Error while getting user-profile for erasure %v", err)
+		return uuid.UUID{}, errs.ErrEraseProfile
+	}
+
+	// Псевдонимы случайные, восстановить по ним исходные данные нельзя
+	pseudonymUserID := uuid.New()
+	query = `
+		UPDATE user_profiles
+		SET user_id = $1,
+		    first_name = 'Erased',
+		    last_name = 'User ' || LEFT(REPLACE(uuid_generate_v4()::text, '-', ''), 12),
+		    city = 'Erased',
+		    erased_at = NOW(),
+		    deleted_at = COALESCE(deleted_at, NOW())
+		WHERE id = $2`
+	if _, err = tx.Exec(ctx, query, pseudonymUserID, profileID); err != nil {
+		logger.Errorf("Error while erasing user-profile %v", err)
+		return uuid.UUID{}, errs.ErrEraseProfile
+	}
+
+	// Номера карт удаляются из хранилища, привязки карт удаляются каскадно
+	if _, err = tx.Exec(ctx, `DELETE FROM card_vault WHERE owner_id = $1`, profileID); err != nil {
+		logger.Errorf("Error while deleting cards of erased user-profile %v", err)
+		return uuid.UUID{}, errs.ErrEraseProfile
+	}
+
+	// История остается, но больше не связана с пользователем. В details могут быть
+	// прежние значения полей профиля, поэтому они удаляются
+	if _, err = tx.Exec(ctx, `UPDATE admin_actions SET user_id = $1 WHERE user_id = $2`, pseudonymUserID, userID); err != nil {
+		logger.Errorf("Error while anonymising admin actions %v", err)
+		return uuid.UUID{}, errs.ErrEraseProfile
+	}
+	if _, err = tx.Exec(ctx, `UPDATE admin_actions SET details = NULL WHERE profile_id = $1`, profileID); err != nil {
+		logger.Errorf("Error while clearing admin action details %v", err)
+		return uuid.UUID{}, errs.ErrEraseProfile
+	}
+	// Журнал изменений остается без значений полей и без ссылок на пользователя,
+	// в том числе на него самого как автора изменений
+	if err = recordProfileAudit(ctx, tx, models.ProfileAuditErase, profileID, userID, nil, nil); err != nil {
+		return uuid.UUID{}, err
+	}
+	query = `
+		UPDATE profile_audit
+		SET user_id = $1, actor_id = NULLIF(actor_id, $3), old_values = NULL, new_values = NULL, source_ip = NULL
+		WHERE profile_id = $2`
+	if _, err = tx.Exec(ctx, query, pseudonymUserID, profileID, userID); err != nil {
+		logger.Errorf("Error while anonymising profile audit %v", err)
+		return uuid.UUID{}, errs.ErrEraseProfile
+	}
+	if _, err = tx.Exec(ctx, `UPDATE revoked_tokens SET user_id = NULL WHERE user_id = $1`, userID); err != nil {
+		logger.Errorf("Error while anonymising revoked tokens %v", err)
+		return uuid.UUID{}, errs.ErrEraseProfile
+	}
+	// Отзыв всех токенов хранится по user_id, обезличить такую строку нельзя
+	if _, err = tx.Exec(ctx, `DELETE FROM revoked_user_tokens WHERE user_id = $1`, userID); err != nil {
+		logger.Errorf("Error while deleting revoked user tokens %v", err)
+		return uuid.UUID{}, errs.ErrEraseProfile
+	}
+	if _, err = tx.Exec(ctx, `UPDATE processed_events SET user_id = NULL WHERE user_id = $1`, userID); err != nil {
+		logger.Errorf("Error while anonymising processed events %v", err)
+		return uuid.UUID{}, errs.ErrEraseProfile
+	}
+	// Сохраненные ответы могут содержать персональные данные. Ключ запроса, который выполняется сейчас
+	// (в том числе самого стирания), остается, в нем еще нет ответа
+	query = `DELETE FROM idempotency_keys WHERE user_id = $1 AND status_code IS NOT NULL`
+	if _, err = tx.Exec(ctx, query, userID); err != nil {
+		logger.Errorf("Error while deleting idempotent responses of erased user-profile %v", err)
+		return uuid.UUID{}, errs.ErrEraseProfile
+	}
+
+	// Еще не опубликованные события пользователя не должны унести персональные данные:
+	// из событий профиля удаляются поля профиля, уведомления об удаленных картах не отправляются
+	query = `
+		UPDATE outbox
+		SET payload = payload - ARRAY['first_name', 'last_name', 'city']
+		WHERE aggregate_id = $1 OR event_key = $2`
+	if _, err = tx.Exec(ctx, query, profileID, userID.String()); err != nil {
+		logger.Errorf("Error while scrubbing outbox events of erased user-profile %v", err)
+		return uuid.UUID{}, errs.ErrEraseProfile
+	}
+	query = `DELETE FROM outbox WHERE event_key = $1 AND event_type = $2`
+	if _, err = tx.Exec(ctx, query, userID.String(), events.CardExpiring); err != nil {
+		logger.Errorf("Error while deleting outbox events of erased user-profile %v", err)
+		return uuid.UUID{}, errs.ErrEraseProfile
+	}
+
+	query = `
+		INSERT INTO erasure_tombstones (user_id_hash)
+		VALUES ($1)
+		ON CONFLICT (user_id_hash) DO UPDATE SET erased_at = NOW()`
+	if _, err = tx.Exec(ctx, query, tombstoneHash(r.tombstoneKey, userID)); err != nil {
+		logger.Errorf("Error while saving erasure tombstone %v", err)
+		return uuid.UUID{}, errs.ErrEraseProfile
+	}
+
+	// Другие сервисы стирают свои данные пользователя по исходному user_id
+	err = recordOutboxEvent(ctx, tx, events.ProfileDeleted, profileID, userID, events.ProfilePayload{
+		ProfileID: profileID,
+		UserID:    userID,
+		Erased:    true,
+	})
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+
+	if adminID != uuid.Nil {
+		err = recordAdminAction(ctx, tx, adminID, models.AdminActionEraseProfile, profileID, pseudonymUserID, nil)
+		if err != nil {
+			return uuid.UUID{}, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		logger.Errorf("Error while committing erasure %v", err)
+		return uuid.UUID{}, errs.ErrEraseProfile
+	}
+	logger.Infof("Erased personal data of user-profile %v", profileID)
+	return profileID, nil
+}
+
+// tombstoneHash - HMAC-SHA256 от user_id для отметки о стирании. Без ключа сервера нельзя
+// проверить, стерт ли известный user_id, а совпадение при повторной регистрации находится
+func tombstoneHash(key []byte, userID uuid.UUID) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(userID[:])
+	return hex.EncodeToString(mac.Sum(nil))
+}
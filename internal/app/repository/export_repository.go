@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	logger "github.com/sirupsen/logrus"
+
+	"service-user/internal/app/errs"
+	"service-user/internal/app/models"
+)
+
+// ExportRepos - репозиторий выгрузки персональных данных
+type ExportRepos struct {
+	db *pgxpool.Pool
+}
+
+// NewExportRepository - конструктор репозитория выгрузки персональных данных
+func NewExportRepository(db *pgxpool.Pool) *ExportRepos {
+	return &ExportRepos{db: db}
+}
+
+// ExportPersonalData - все данные пользователя из одного снимка базы. Выгрузка только читает данные,
+// поэтому выполняется в транзакции REPEATABLE READ READ ONLY и корзину не создает
+func (r *ExportRepos) ExportPersonalData(ctx context.Context, userID uuid.UUID) (models.PersonalDataExport, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		logger.Errorf("Error while starting transaction %v", err)
+		return models.PersonalDataExport{}, errs.ErrExportPersonalData
+	}
+	defer tx.Rollback(ctx)
+
+	profile, err := getProfile(ctx, tx, userID)
+	if err != nil {
+		return models.PersonalDataExport{}, err
+	}
+
+	cart, err := getCart(ctx, tx, userID)
+	if err != nil {
+		return models.PersonalDataExport{}, err
+	}
+
+	cards, err := getCards(ctx, tx, userID)
+	if err != nil {
+		return models.PersonalDataExport{}, err
+	}
+
+	audit, err := getProfileAdminActions(ctx, tx, userID)
+	if err != nil {
+		return models.PersonalDataExport{}, err
+	}
+
+	history, err := getProfileHistory(ctx, tx, userID)
+	if err != nil {
+		return models.PersonalDataExport{}, err
+	}
+
+	return models.PersonalDataExport{
+		Profile:      profile,
+		Cart:         cart,
+		Cards:        cards,
+		AuditHistory: audit,
+		ChangeLog:    history,
+	}, nil
+}
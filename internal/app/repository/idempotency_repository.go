@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	logger "github.com/sirupsen/logrus"
+
+	"service-user/internal/app/errs"
+	"service-user/internal/app/models"
+)
+
+// IdempotencyRepos - репозиторий ответов на запросы с Idempotency-Key
+type IdempotencyRepos struct {
+	db *pgxpool.Pool
+}
+
+// NewIdempotencyRepository - конструктор репозитория ответов на запросы с Idempotency-Key
+func NewIdempotencyRepository(db *pgxpool.Pool) *IdempotencyRepos {
+	return &IdempotencyRepos{db: db}
+}
+
+// ReserveIdempotencyKey - занимает ключ для запроса с токеном reservation на время аренды lease. Если ключ
+// уже занят и аренда или срок хранения ответа не истекли, возвращает его запись, иначе nil
+func (r *IdempotencyRepos) ReserveIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, reservation uuid.UUID, requestHash string, lease time.Duration) (*models.IdempotencyRecord, error) {
+	// Истекший ключ и ключ, аренда которого истекла без ответа (экземпляр упал), занимаются заново, как новые.
+	// До сохранения ответа запись хранится до конца аренды
+	query := `
+		INSERT INTO idempotency_keys (user_id, idempotency_key, request_hash, reservation_token, locked_until, expires_at)
+		VALUES ($1, $2, $3, $4, NOW() + make_interval(secs => $5), NOW() + make_interval(secs => $5))
+		ON CONFLICT (user_id, idempotency_key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash,
+		    status_code = NULL,
+		    headers = NULL,
+		    response_body = NULL,
+		    created_at = NOW(),
+		    reservation_token = EXCLUDED.reservation_token,
+		    locked_until = EXCLUDED.locked_until,
+		    expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= NOW()
+		   OR (idempotency_keys.status_code IS NULL AND idempotency_keys.locked_until <= NOW())`
+	tag, err := r.db.Exec(ctx, query, userID, key, requestHash, reservation, lease.Seconds())
+	if err != nil {
+		logger.Errorf("Error while reserving idempotency key %v", err)
+		return nil, errs.ErrIdempotencyStore
+	}
+	if tag.RowsAffected() == 1 {
+		return nil, nil
+	}
+
+	var record models.IdempotencyRecord
+	var statusCode *int
+	var headers, body []byte
+	query = `
+		SELECT request_hash, status_code, headers, response_body
+		FROM idempotency_keys
+		WHERE user_id = $1 AND idempotency_key = $2`
+	err = r.db.QueryRow(ctx, query, userID, key).Scan(&record.RequestHash, &statusCode, &headers, &body)
+	if err != nil {
+		// Ключ освободили между запросами: выполняющийся запрос завершился ошибкой
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.ErrIdempotencyKeyInProgress
+		}
+		logger.Errorf("Error while getting idempotency key %v", err)
+		return nil, errs.ErrIdempotencyStore
+	}
+	if statusCode == nil {
+		return &record, nil
+	}
+
+	record.Response = &models.IdempotentResponse{StatusCode: *statusCode, Body: body}
+	if len(headers) > 0 {
+		if err = json.Unmarshal(headers, &record.Response.Headers); err != nil {
+			logger.Errorf("Error while decoding idempotent response headers %v", err)
+			return nil, errs.ErrIdempotencyStore
+		}
+	}
+	return &record, nil
+}
+
+// SaveIdempotentResponse - сохраняет ответ на запрос, занявший ключ с токеном reservation, и снимает аренду.
+// Ответ хранится ttl. Если аренда истекла и ключ занял другой запрос, ответ не сохраняется
+func (r *IdempotencyRepos) SaveIdempotentResponse(ctx context.Context, userID uuid.UUID, key string, reservation uuid.UUID, response models.IdempotentResponse, ttl time.Duration) error {
+	headers, err := json.Marshal(response.Headers)
+	if err != nil {
+		logger.Errorf("Error while encoding idempotent response headers %v", err)
+		return errs.ErrIdempotencyStore
+	}
+
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $3, headers = $4, response_body = $5,
+		    locked_until = NULL,
+		    expires_at = NOW() + make_interval(secs => $6)
+		WHERE user_id = $1 AND idempotency_key = $2 AND reservation_token = $7 AND status_code IS NULL`
+	tag, err := r.db.Exec(ctx, query, userID, key, response.StatusCode, headers, response.Body, ttl.Seconds(), reservation)
+	if err != nil {
+		logger.Errorf("Error while saving idempotent response %v", err)
+		return errs.ErrIdempotencyStore
+	}
+	if tag.RowsAffected() == 0 {
+		logger.Warnf("Idempotency key of user %v is reserved by another request, response is not saved", userID)
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey - освобождает ключ запроса, который не удалось выполнить, чтобы его можно было повторить.
+// Освобождается только аренда этого запроса (reservation), ключ, занятый после нее другим запросом, остается
+func (r *IdempotencyRepos) ReleaseIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, reservation uuid.UUID) error {
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE user_id = $1 AND idempotency_key = $2 AND reservation_token = $3 AND status_code IS NULL`
+	_, err := r.db.Exec(ctx, query, userID, key, reservation)
+	if err != nil {
+		logger.Errorf("Error while releasing idempotency key %v", err)
+		return errs.ErrIdempotencyStore
+	}
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys - удаляет истекшие ключи, возвращает их количество
+func (r *IdempotencyRepos) DeleteExpiredIdempotencyKeys(ctx context.Context) (int, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= NOW()`)
+	if err != nil {
+		logger.Errorf("Error while deleting expired idempotency keys %v", err)
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
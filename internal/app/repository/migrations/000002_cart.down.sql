@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_cart_items_cart_id_product_id;
+
+ALTER TABLE cart_items DROP COLUMN IF EXISTS updated_at;
+
+DROP INDEX IF EXISTS idx_cart_user_profile_id;
@@ -0,0 +1,8 @@
+-- У профиля может быть только одна корзина
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cart_user_profile_id ON cart(user_profile_id);
+
+-- Триггер set_timestamp_cart_items обновляет updated_at, которого в cart_items не было
+ALTER TABLE cart_items ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT NOW();
+
+-- Один товар занимает одну позицию в корзине, повторное добавление увеличивает количество
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cart_items_cart_id_product_id ON cart_items(cart_id, product_id);
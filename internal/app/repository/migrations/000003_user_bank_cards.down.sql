@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS set_timestamp_user_bank_cards ON user_bank_cards;
+
+DROP TABLE IF EXISTS user_bank_cards;
@@ -0,0 +1,19 @@
+-- банковские карты пользователя
+CREATE TABLE IF NOT EXISTS user_bank_cards (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_profile_id UUID NOT NULL,
+    card_number VARCHAR(19) NOT NULL,
+    expiration_date VARCHAR(5) NOT NULL,
+    card_holder_name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    FOREIGN KEY (user_profile_id) REFERENCES user_profiles(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_user_bank_cards_user_profile_id ON user_bank_cards(user_profile_id);
+
+-- Триггер для user_bank_cards
+CREATE TRIGGER set_timestamp_user_bank_cards
+    BEFORE UPDATE ON user_bank_cards
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_user_bank_cards_key_id;
+
+-- Зашифрованные номера без мастер-ключа восстановить нельзя
+DELETE FROM user_bank_cards WHERE card_number IS NULL;
+
+ALTER TABLE user_bank_cards ALTER COLUMN card_number SET NOT NULL;
+
+ALTER TABLE user_bank_cards
+    DROP COLUMN IF EXISTS card_number_encrypted,
+    DROP COLUMN IF EXISTS data_key,
+    DROP COLUMN IF EXISTS key_id,
+    DROP COLUMN IF EXISTS card_last4;
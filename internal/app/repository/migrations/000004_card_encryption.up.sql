@@ -0,0 +1,14 @@
+-- Номер карты хранится только в зашифрованном виде (конвертное шифрование)
+ALTER TABLE user_bank_cards
+    ADD COLUMN card_number_encrypted BYTEA,
+    ADD COLUMN data_key BYTEA,
+    ADD COLUMN key_id VARCHAR(64),
+    ADD COLUMN card_last4 VARCHAR(4);
+
+-- Открытые номера старых строк шифрует команда cmd/reencrypt
+UPDATE user_bank_cards SET card_last4 = right(card_number, 4);
+
+ALTER TABLE user_bank_cards ALTER COLUMN card_number DROP NOT NULL;
+ALTER TABLE user_bank_cards ALTER COLUMN card_last4 SET NOT NULL;
+
+CREATE INDEX idx_user_bank_cards_key_id ON user_bank_cards(key_id);
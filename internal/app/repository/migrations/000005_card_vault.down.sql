@@ -0,0 +1,29 @@
+ALTER TABLE user_bank_cards
+    ADD COLUMN card_number VARCHAR(19),
+    ADD COLUMN card_number_encrypted BYTEA,
+    ADD COLUMN data_key BYTEA,
+    ADD COLUMN key_id VARCHAR(64),
+    ADD COLUMN card_last4 VARCHAR(4);
+
+UPDATE user_bank_cards c
+SET card_number_encrypted = v.card_number_encrypted,
+    data_key = v.data_key,
+    key_id = v.key_id,
+    card_last4 = v.card_last4
+FROM card_vault v
+WHERE v.token = c.card_token AND v.key_id <> 'plaintext';
+
+-- Незашифрованные номера возвращаются в card_number, как до миграции
+UPDATE user_bank_cards c
+SET card_number = convert_from(v.card_number_encrypted, 'UTF8'),
+    card_last4 = v.card_last4
+FROM card_vault v
+WHERE v.token = c.card_token AND v.key_id = 'plaintext';
+
+ALTER TABLE user_bank_cards ALTER COLUMN card_last4 SET NOT NULL;
+
+CREATE INDEX idx_user_bank_cards_key_id ON user_bank_cards(key_id);
+
+ALTER TABLE user_bank_cards DROP COLUMN IF EXISTS card_token;
+
+DROP TABLE IF EXISTS card_vault;
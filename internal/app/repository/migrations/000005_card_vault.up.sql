@@ -0,0 +1,49 @@
+-- Хранилище номеров карт: остальной сервис работает только с токеном карты
+CREATE TABLE IF NOT EXISTS card_vault (
+    token UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner_id UUID NOT NULL,
+    fingerprint VARCHAR(64),
+    card_number_encrypted BYTEA NOT NULL,
+    data_key BYTEA NOT NULL,
+    key_id VARCHAR(64) NOT NULL,
+    card_last4 VARCHAR(4) NOT NULL,
+    brand VARCHAR(32),
+    -- Номер зашифрован с привязкой к владельцу и токену (AAD), чтобы шифротекст нельзя было перенести в чужую строку
+    aad_bound BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    FOREIGN KEY (owner_id) REFERENCES user_profiles(id) ON DELETE CASCADE
+);
+
+-- Одна и та же карта не может быть добавлена в профиль дважды
+CREATE UNIQUE INDEX idx_card_vault_owner_id_fingerprint ON card_vault(owner_id, fingerprint);
+
+CREATE INDEX idx_card_vault_key_id ON card_vault(key_id);
+
+-- Переносим номера в хранилище. Номера, которые еще не были зашифрованы (до make reencrypt-cards),
+-- переносятся как есть с key_id 'plaintext', шифровать в SQL нечем: мастер-ключ есть только у сервиса.
+-- После миграции make reencrypt-cards шифрует их и заполняет отпечатки и платежную систему всех
+-- перенесенных карт, до этого сервис читает такие номера без расшифровки.
+ALTER TABLE user_bank_cards ADD COLUMN card_token UUID;
+
+UPDATE user_bank_cards SET card_token = uuid_generate_v4();
+
+INSERT INTO card_vault (token, owner_id, card_number_encrypted, data_key, key_id, card_last4, created_at)
+SELECT card_token,
+       user_profile_id,
+       COALESCE(card_number_encrypted, convert_to(card_number, 'UTF8')),
+       COALESCE(data_key, ''::BYTEA),
+       CASE WHEN card_number_encrypted IS NULL THEN 'plaintext' ELSE key_id END,
+       card_last4,
+       created_at
+FROM user_bank_cards;
+
+ALTER TABLE user_bank_cards
+    ALTER COLUMN card_token SET NOT NULL,
+    ADD CONSTRAINT fk_user_bank_cards_card_token FOREIGN KEY (card_token) REFERENCES card_vault(token) ON DELETE CASCADE,
+    DROP COLUMN card_number,
+    DROP COLUMN card_number_encrypted,
+    DROP COLUMN data_key,
+    DROP COLUMN key_id,
+    DROP COLUMN card_last4;
+
+CREATE UNIQUE INDEX idx_user_bank_cards_card_token ON user_bank_cards(card_token);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_user_bank_cards_expiry_not_notified;
+DROP INDEX IF EXISTS idx_user_bank_cards_default;
+
+ALTER TABLE user_bank_cards
+    DROP COLUMN IF EXISTS expiry_notified_at,
+    DROP COLUMN IF EXISTS is_default;
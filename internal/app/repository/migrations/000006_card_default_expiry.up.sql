@@ -0,0 +1,19 @@
+ALTER TABLE user_bank_cards
+    ADD COLUMN is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN expiry_notified_at TIMESTAMP;
+
+-- Картой по умолчанию у существующих профилей становится первая добавленная карта
+UPDATE user_bank_cards c
+SET is_default = TRUE
+WHERE c.id = (
+    SELECT f.id FROM user_bank_cards f
+    WHERE f.user_profile_id = c.user_profile_id
+    ORDER BY f.created_at, f.id
+    LIMIT 1
+);
+
+-- У профиля не больше одной карты по умолчанию
+CREATE UNIQUE INDEX idx_user_bank_cards_default ON user_bank_cards(user_profile_id) WHERE is_default;
+
+-- Поиск карт, о скором истечении которых еще не уведомляли
+CREATE INDEX idx_user_bank_cards_expiry_not_notified ON user_bank_cards(expiration_date) WHERE expiry_notified_at IS NULL;
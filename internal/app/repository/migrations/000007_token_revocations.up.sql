@@ -0,0 +1,15 @@
+-- Отозванные токены по jti, строка нужна только до истечения токена
+CREATE TABLE revoked_tokens (
+    jti VARCHAR(255) PRIMARY KEY,
+    user_id UUID,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_revoked_tokens_expires_at ON revoked_tokens(expires_at);
+
+-- Все токены пользователя, выпущенные раньше revoked_before, считаются отозванными
+CREATE TABLE revoked_user_tokens (
+    user_id UUID PRIMARY KEY,
+    revoked_before TIMESTAMP NOT NULL
+);
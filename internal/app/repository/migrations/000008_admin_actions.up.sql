@@ -0,0 +1,14 @@
+-- Журнал действий администраторов с профилями пользователей.
+-- Без внешних ключей: запись должна пережить удаление профиля
+CREATE TABLE admin_actions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    admin_id UUID NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    profile_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    details JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_admin_actions_admin_id ON admin_actions(admin_id, created_at);
+CREATE INDEX idx_admin_actions_profile_id ON admin_actions(profile_id, created_at);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_user_profiles_lower_first_name;
+DROP INDEX IF EXISTS idx_user_profiles_lower_last_name;
+DROP INDEX IF EXISTS idx_user_profiles_city_created_at_id;
+DROP INDEX IF EXISTS idx_user_profiles_last_name_id;
+DROP INDEX IF EXISTS idx_user_profiles_created_at_id;
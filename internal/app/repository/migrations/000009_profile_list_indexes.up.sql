@@ -0,0 +1,8 @@
+-- Keyset пагинация списка профилей: сортировка по дате создания или фамилии с id для однозначности
+CREATE INDEX idx_user_profiles_created_at_id ON user_profiles(created_at, id);
+CREATE INDEX idx_user_profiles_last_name_id ON user_profiles(last_name, id);
+CREATE INDEX idx_user_profiles_city_created_at_id ON user_profiles(city, created_at, id);
+
+-- Поиск по префиксу имени и фамилии без учета регистра
+CREATE INDEX idx_user_profiles_lower_last_name ON user_profiles(LOWER(last_name) text_pattern_ops);
+CREATE INDEX idx_user_profiles_lower_first_name ON user_profiles(LOWER(first_name) text_pattern_ops);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_user_profiles_search_text_trgm;
+
+ALTER TABLE user_profiles DROP COLUMN IF EXISTS search_text;
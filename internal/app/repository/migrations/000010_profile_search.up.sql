@@ -0,0 +1,7 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Имя, фамилия и город одной строкой для нечеткого поиска
+ALTER TABLE user_profiles
+    ADD COLUMN search_text TEXT GENERATED ALWAYS AS (LOWER(first_name || ' ' || last_name || ' ' || city)) STORED;
+
+CREATE INDEX idx_user_profiles_search_text_trgm ON user_profiles USING GIN (search_text gin_trgm_ops);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_user_profiles_deleted_at;
+
+-- Удаленные профили не переживают откат, как и до появления мягкого удаления
+DELETE FROM user_profiles WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE user_profiles DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,4 @@
+ALTER TABLE user_profiles ADD COLUMN deleted_at TIMESTAMP;
+
+-- Поиск профилей для окончательного удаления после срока восстановления
+CREATE INDEX idx_user_profiles_deleted_at ON user_profiles(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS erasure_tombstones;
+
+ALTER TABLE user_profiles
+    DROP COLUMN IF EXISTS recreated_after_erasure,
+    DROP COLUMN IF EXISTS erased_at;
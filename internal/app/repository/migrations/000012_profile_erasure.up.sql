@@ -0,0 +1,11 @@
+-- Стертый профиль остается без персональных данных, чтобы не ломать историю корзины и аналитику
+ALTER TABLE user_profiles
+    ADD COLUMN erased_at TIMESTAMP,
+    ADD COLUMN recreated_after_erasure BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Отметки о стертых пользователях: хранится только HMAC-SHA256 от user_id с ключом сервиса
+-- (profiles.erasure_tombstone_key), по нему определяется, что профиль создан повторно после стирания
+CREATE TABLE erasure_tombstones (
+    user_id_hash CHAR(64) PRIMARY KEY,
+    erased_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,16 @@
+-- Журнал изменений профиля, пишется в одной транзакции с изменением
+CREATE TABLE profile_audit (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    profile_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    actor_id UUID,
+    action VARCHAR(20) NOT NULL,
+    request_id VARCHAR(255),
+    old_values JSONB,
+    new_values JSONB,
+    source_ip VARCHAR(45),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_profile_audit_user_id ON profile_audit(user_id, created_at);
+CREATE INDEX idx_profile_audit_profile_id ON profile_audit(profile_id);
@@ -0,0 +1,17 @@
+-- Доменные события, записываются в одной транзакции с изменением и публикуются фоновой задачей.
+-- Опубликованное событие удаляется, поэтому в таблице только события, ожидающие публикации
+CREATE TABLE outbox (
+    seq BIGSERIAL PRIMARY KEY,
+    id UUID NOT NULL UNIQUE,
+    event_type VARCHAR(50) NOT NULL,
+    aggregate_id UUID NOT NULL,
+    payload JSONB NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_error TEXT
+);
+
+-- События одного профиля публикуются по порядку
+CREATE INDEX idx_outbox_aggregate_id ON outbox(aggregate_id, seq);
+CREATE INDEX idx_outbox_next_attempt_at ON outbox(next_attempt_at);
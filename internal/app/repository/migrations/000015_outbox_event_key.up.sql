@@ -0,0 +1,2 @@
+-- Ключ сообщения в брокере (user_id), события одного пользователя попадают в одну партицию
+ALTER TABLE outbox ADD COLUMN event_key VARCHAR(255) NOT NULL DEFAULT '';
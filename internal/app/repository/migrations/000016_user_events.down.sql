@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS processed_events;
+ALTER TABLE user_profiles DROP COLUMN IF EXISTS skeleton;
@@ -0,0 +1,13 @@
+-- Профиль, созданный по событию регистрации в сервисе авторизации, пока пользователь его не заполнил
+ALTER TABLE user_profiles ADD COLUMN skeleton BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Обработанные входящие события, повторно доставленное событие пропускается.
+-- user_id очищается при стирании данных пользователя, id события остается для отсева повторов
+CREATE TABLE processed_events (
+    event_id UUID PRIMARY KEY,
+    event_type VARCHAR(50) NOT NULL,
+    user_id UUID,
+    processed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_processed_events_user_id ON processed_events(user_id);
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS set_version_user_profiles ON user_profiles;
+DROP FUNCTION IF EXISTS increment_version_column();
+ALTER TABLE user_profiles DROP COLUMN IF EXISTS version;
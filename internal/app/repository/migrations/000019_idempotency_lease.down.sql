@@ -0,0 +1,3 @@
+ALTER TABLE idempotency_keys
+    DROP COLUMN IF EXISTS reservation_token,
+    DROP COLUMN IF EXISTS locked_until;
@@ -0,0 +1,11 @@
+-- Выполняющийся запрос занимает ключ только до locked_until: если экземпляр упал, не сохранив ответ
+-- и не освободив ключ, после аренды запрос с этим ключом выполняется заново. Срок хранения ответа
+-- (expires_at) отсчитывается от его сохранения
+ALTER TABLE idempotency_keys ADD COLUMN locked_until TIMESTAMP;
+
+-- Токен запроса, занявшего ключ: после окончания аренды ключ может занять другой запрос,
+-- и прежний запрос не должен сохранить или освободить чужую аренду
+ALTER TABLE idempotency_keys ADD COLUMN reservation_token UUID;
+
+-- Ключи, оставшиеся занятыми после паники или падения экземпляра, освобождаются сразу
+UPDATE idempotency_keys SET locked_until = created_at WHERE status_code IS NULL;
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	logger "github.com/sirupsen/logrus"
+
+	"service-user/internal/app/errs"
+	"service-user/internal/app/events"
+	"service-user/internal/app/models"
+)
+
+// OutboxRepos - репозиторий доменных событий, ожидающих публикации
+type OutboxRepos struct {
+	db *pgxpool.Pool
+}
+
+// NewOutboxRepository - конструктор репозитория outbox
+func NewOutboxRepository(db *pgxpool.Pool) *OutboxRepos {
+	return &OutboxRepos{db: db}
+}
+
+// recordOutboxEvent - запись доменного события пользователя userID в outbox в транзакции изменения,
+// событие будет опубликовано только если транзакция зафиксирована
+func recordOutboxEvent(ctx context.Context, tx pgx.Tx, eventType string, aggregateID uuid.UUID, userID uuid.UUID, payload interface{}) error {
+	event := events.NewEvent(eventType, payload)
+	payloadJSON, err := json.Marshal(event.Payload)
+	if err != nil {
+		logger.Errorf("Error while encoding %s event %v", eventType, err)
+		return errs.ErrRecordEvent
+	}
+
+	query := `
+		INSERT INTO outbox (id, event_type, aggregate_id, event_key, payload, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err = tx.Exec(ctx, query, event.ID, event.Type, aggregateID, userID.String(), payloadJSON, event.OccurredAt)
+	if err != nil {
+		logger.Errorf("Error while recording %s event %v", eventType, err)
+		return errs.ErrRecordEvent
+	}
+	return nil
+}
+
+// ClaimOutboxEvents - забирает события для публикации на время lease, чтобы их не опубликовал
+// другой экземпляр. Событие профиля выдается, только когда опубликованы все предыдущие события этого профиля
+func (r *OutboxRepos) ClaimOutboxEvents(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	query := `
+		WITH claimed AS (
+			UPDATE outbox
+			SET next_attempt_at = NOW() + make_interval(secs => $2)
+			WHERE seq IN (
+				SELECT o.seq FROM outbox o
+				WHERE o.next_attempt_at <= NOW()
+				  AND NOT EXISTS (SELECT 1 FROM outbox p WHERE p.aggregate_id = o.aggregate_id AND p.seq < o.seq)
+				ORDER BY o.seq
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING seq, id, event_type, aggregate_id, event_key, payload, occurred_at, attempts
+		)
+		SELECT id, event_type, aggregate_id, event_key, payload, occurred_at, attempts FROM claimed ORDER BY seq`
+	rows, err := r.db.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		logger.Errorf("Error while claiming outbox events %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var claimed []models.OutboxEvent
+	for rows.Next() {
+		var event models.OutboxEvent
+		var payload []byte
+		err = rows.Scan(&event.ID, &event.Type, &event.AggregateID, &event.Key, &payload, &event.OccurredAt, &event.Attempts)
+		if err != nil {
+			logger.Errorf("Error while scanning outbox event %v", err)
+			return nil, err
+		}
+		event.Payload = payload
+		claimed = append(claimed, event)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Errorf("Error while reading outbox events %v", err)
+		return nil, err
+	}
+	return claimed, nil
+}
+
+// MarkOutboxEventPublished - удаляет опубликованное событие
+func (r *OutboxRepos) MarkOutboxEventPublished(ctx context.Context, eventID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM outbox WHERE id = $1`, eventID)
+	if err != nil {
+		logger.Errorf("Error while deleting published outbox event %v", err)
+		return err
+	}
+	return nil
+}
+
+// RetryOutboxEvent - откладывает повторную публикацию события на delay после неудачи
+func (r *OutboxRepos) RetryOutboxEvent(ctx context.Context, eventID uuid.UUID, delay time.Duration, reason string) error {
+	query := `
+		UPDATE outbox
+		SET attempts = attempts + 1, next_attempt_at = NOW() + make_interval(secs => $2), last_error = $3
+		WHERE id = $1`
+	_, err := r.db.Exec(ctx, query, eventID, delay.Seconds(), reason)
+	if err != nil {
+		logger.Errorf("Error while rescheduling outbox event %v", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	logger "github.com/sirupsen/logrus"
+
+	"service-user/internal/app/errs"
+	"service-user/internal/app/models"
+)
+
+// profileFields - поля профиля в журнале изменений
+type profileFields map[string]string
+
+// requestedFields - поля, переданные в частичном обновлении профиля
+func requestedFields(profile models.UserProfileUpdate) profileFields {
+	fields := profileFields{}
+	if profile.FirstName != "" {
+		fields["first_name"] = profile.FirstName
+	}
+	if profile.LastName != "" {
+		fields["last_name"] = profile.LastName
+	}
+	if profile.City != "" {
+		fields["city"] = profile.City
+	}
+	return fields
+}
+
+// changedFields - старые и новые значения только тех полей, которые изменились
+func changedFields(before, after profileFields) (profileFields, profileFields) {
+	oldValues, newValues := profileFields{}, profileFields{}
+	for name, value := range after {
+		if before[name] != value {
+			oldValues[name] = before[name]
+			newValues[name] = value
+		}
+	}
+	return oldValues, newValues
+}
+
+// recordProfileAudit - запись изменения профиля в журнал в транзакции изменения.
+// Автор, id запроса и адрес берутся из контекста запроса
+func recordProfileAudit(ctx context.Context, tx pgx.Tx, action string, profileID uuid.UUID, userID uuid.UUID, oldValues, newValues profileFields) error {
+	meta := models.RequestMetaFromContext(ctx)
+
+	var actorID *uuid.UUID
+	if meta.ActorID != uuid.Nil {
+		actorID = &meta.ActorID
+	}
+
+	query := `
+		INSERT INTO profile_audit (profile_id, user_id, actor_id, action, request_id, old_values, new_values, source_ip)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, NULLIF($8, ''))`
+	_, err := tx.Exec(ctx, query, profileID, userID, actorID, action, meta.RequestID,
+		auditJSON(oldValues), auditJSON(newValues), meta.SourceIP)
+	if err != nil {
+		logger.Errorf("Error while recording profile audit %v", err)
+		return errs.ErrProfileAudit
+	}
+	return nil
+}
+
+// auditJSON - значения полей в JSON, пустой набор сохраняется как NULL
+func auditJSON(values profileFields) []byte {
+	if len(values) == 0 {
+		return nil
+	}
+	data, _ := json.Marshal(values)
+	return data
+}
+
+// GetProfileHistory - журнал изменений профиля пользователя, от новых к старым
+func (r *ProfileRepos) GetProfileHistory(ctx context.Context, userID uuid.UUID) ([]models.ProfileAuditRecord, error) {
+	return getProfileHistory(ctx, r.db, userID)
+}
+
+func getProfileHistory(ctx context.Context, q querier, userID uuid.UUID) ([]models.ProfileAuditRecord, error) {
+	query := `
+		SELECT id, action, actor_id, COALESCE(request_id, ''), old_values, new_values, COALESCE(source_ip, ''), created_at
+		FROM profile_audit
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id`
+	rows, err := q.Query(ctx, query, userID)
+	if err != nil {
+		logger.Errorf("Error while getting profile history %v", err)
+		return nil, errs.ErrGetAuditHistory
+	}
+	defer rows.Close()
+
+	records := []models.ProfileAuditRecord{}
+	for rows.Next() {
+		var record models.ProfileAuditRecord
+		var oldValues, newValues []byte
+		err = rows.Scan(&record.ID, &record.Action, &record.ActorID, &record.RequestID, &oldValues, &newValues,
+			&record.SourceIP, &record.CreatedAt)
+		if err != nil {
+			logger.Errorf("Error while scanning profile history %v", err)
+			return nil, errs.ErrGetAuditHistory
+		}
+		record.OldValues, record.NewValues = oldValues, newValues
+		records = append(records, record)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Errorf("Error while reading profile history %v", err)
+		return nil, errs.ErrGetAuditHistory
+	}
+	return records, nil
+}
@@ -5,13 +5,16 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	logger "github.com/sirupsen/logrus"
 
 	"service-user/internal/app/errs"
+	"service-user/internal/app/events"
 	"service-user/internal/app/models"
 )
 
@@ -21,43 +24,138 @@ const (
 
 // ProfileRepos - структура для работы с базой данных через pgxpool
 type ProfileRepos struct {
-	db *pgxpool.Pool
+	db           *pgxpool.Pool
+	tombstoneKey []byte
 }
 
-// NewProfileRepository - конструктор для создания нового репозитория
-func NewProfileRepository(db *pgxpool.Pool) *ProfileRepos {
-	return &ProfileRepos{db: db}
+// NewProfileRepository - конструктор для создания нового репозитория,
+// tombstoneKey - ключ HMAC отметок о стертых пользователях
+func NewProfileRepository(db *pgxpool.Pool, tombstoneKey []byte) *ProfileRepos {
+	return &ProfileRepos{db: db, tombstoneKey: tombstoneKey}
 }
 
 // CreateProfile - создание профиля пользователя
 func (r *ProfileRepos) CreateProfile(ctx context.Context, profile models.UserProfileInput) (uuid.UUID, error) {
-	query := `
-		INSERT INTO user_profiles (user_id, first_name, last_name, city)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id`
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Errorf("Error while starting transaction %v", err)
+		return uuid.UUID{}, errs.ErrCreateUserProfile
+	}
+	defer tx.Rollback(ctx)
+
+	// Профиль, созданный по событию регистрации, заполняется данными пользователя
+	var skeletonID uuid.UUID
+	var firstName, lastName, city string
+	query := `SELECT id, first_name, last_name, city FROM user_profiles WHERE user_id = $1 AND skeleton AND deleted_at IS NULL FOR UPDATE`
+	err = tx.QueryRow(ctx, query, profile.UserID).Scan(&skeletonID, &firstName, &lastName, &city)
+	if err == nil {
+		skeleton := profileFields{"first_name": firstName, "last_name": lastName, "city": city}
+		return completeSkeletonProfile(ctx, tx, skeletonID, skeleton, profile)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		logger.Errorf("Error while getting user-profile %v", err)
+		return uuid.UUID{}, errs.ErrCreateUserProfile
+	}
+
+	// Отметка о стирании означает, что пользователь уже был и его данные стерты по запросу
+	query = `
+		INSERT INTO user_profiles (user_id, first_name, last_name, city, recreated_after_erasure)
+		VALUES ($1, $2, $3, $4, EXISTS (SELECT 1 FROM erasure_tombstones WHERE user_id_hash = $5))
+		RETURNING id, recreated_after_erasure`
 	var id uuid.UUID
-	err := r.db.QueryRow(ctx, query, profile.UserID, profile.FirstName, profile.LastName, profile.City).Scan(&id)
+	var recreated bool
+	err = tx.QueryRow(ctx, query, profile.UserID, profile.FirstName, profile.LastName, profile.City,
+		tombstoneHash(r.tombstoneKey, profile.UserID)).Scan(&id, &recreated)
 	if err != nil {
 		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			if pgErr.Code == DuplicateValue {
-				return uuid.UUID{}, errs.ErrProfileAlreadyExists
+		if errors.As(err, &pgErr) && pgErr.Code == DuplicateValue {
+			// Удаленный профиль занимает user_id до окончательного удаления, его можно только восстановить
+			var deleted bool
+			query = `SELECT deleted_at IS NOT NULL FROM user_profiles WHERE user_id = $1`
+			if r.db.QueryRow(ctx, query, profile.UserID).Scan(&deleted) == nil && deleted {
+				return uuid.UUID{}, errs.ErrProfileDeleted
 			}
-			logger.Errorf("Error while inserting user-profile %v", err)
-			return uuid.UUID{}, errs.ErrCreateUserProfile
+			return uuid.UUID{}, errs.ErrProfileAlreadyExists
 		}
+		logger.Errorf("Error while inserting user-profile %v", err)
+		return uuid.UUID{}, errs.ErrCreateUserProfile
+	}
+
+	newValues := profileFields{"first_name": profile.FirstName, "last_name": profile.LastName, "city": profile.City}
+	if err = recordProfileAudit(ctx, tx, models.ProfileAuditCreate, id, profile.UserID, nil, newValues); err != nil {
+		return uuid.UUID{}, err
+	}
+	err = recordOutboxEvent(ctx, tx, events.ProfileCreated, id, profile.UserID, events.ProfilePayload{
+		ProfileID: id,
+		UserID:    profile.UserID,
+		FirstName: profile.FirstName,
+		LastName:  profile.LastName,
+		City:      profile.City,
+	})
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		logger.Errorf("Error while committing user-profile %v", err)
+		return uuid.UUID{}, errs.ErrCreateUserProfile
+	}
+
+	if recreated {
+		logger.Warnf("User-profile %v is created by a user whose data was erased before", id)
 	}
 	logger.Infof("Created user-profile %v", id)
 	return id, nil
 }
 
+// completeSkeletonProfile - заполнение профиля, созданного по событию регистрации. До заполнения профиль
+// не виден ни пользователю, ни подписчикам событий, поэтому для них это создание профиля.
+// В журнале изменений остаются значения, пришедшие из сервиса авторизации
+func completeSkeletonProfile(ctx context.Context, tx pgx.Tx, profileID uuid.UUID, skeleton profileFields, profile models.UserProfileInput) (uuid.UUID, error) {
+	query := `
+		UPDATE user_profiles
+		SET first_name = $2, last_name = $3, city = $4, skeleton = FALSE
+		WHERE id = $1`
+	if _, err := tx.Exec(ctx, query, profileID, profile.FirstName, profile.LastName, profile.City); err != nil {
+		logger.Errorf("Error while completing user-profile %v", err)
+		return uuid.UUID{}, errs.ErrCreateUserProfile
+	}
+
+	oldValues, newValues := changedFields(skeleton,
+		profileFields{"first_name": profile.FirstName, "last_name": profile.LastName, "city": profile.City})
+	err := recordProfileAudit(ctx, tx, models.ProfileAuditUpdate, profileID, profile.UserID, oldValues, newValues)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	err = recordOutboxEvent(ctx, tx, events.ProfileCreated, profileID, profile.UserID, events.ProfilePayload{
+		ProfileID: profileID,
+		UserID:    profile.UserID,
+		FirstName: profile.FirstName,
+		LastName:  profile.LastName,
+		City:      profile.City,
+	})
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		logger.Errorf("Error while committing user-profile %v", err)
+		return uuid.UUID{}, errs.ErrCreateUserProfile
+	}
+	logger.Infof("Completed user-profile %v", profileID)
+	return profileID, nil
+}
+
 // GetProfile - получение профиля пользователя по userID
 func (r *ProfileRepos) GetProfile(ctx context.Context, userID uuid.UUID) (models.UserProfileOut, error) {
-	query := `SELECT id, user_id, first_name, last_name, city, created_at, updated_at FROM user_profiles WHERE user_id = $1`
-	row := r.db.QueryRow(ctx, query, userID)
+	return getProfile(ctx, r.db, userID)
+}
+
+func getProfile(ctx context.Context, q querier, userID uuid.UUID) (models.UserProfileOut, error) {
+	// Незаполненный профиль, созданный по событию регистрации, не показывается, пока его не заполнит пользователь
+	query := `SELECT id, user_id, first_name, last_name, city, created_at, updated_at, version FROM user_profiles WHERE user_id = $1 AND deleted_at IS NULL AND NOT skeleton`
+	row := q.QueryRow(ctx, query, userID)
 
 	var profile models.UserProfileOut
-	err := row.Scan(&profile.ID, &profile.UserID, &profile.FirstName, &profile.LastName, &profile.City, &profile.CreatedAt, &profile.UpdatedAt)
+	err := row.Scan(&profile.ID, &profile.UserID, &profile.FirstName, &profile.LastName, &profile.City, &profile.CreatedAt, &profile.UpdatedAt, &profile.Version)
 	if err != nil {
 		if err.Error() == "no rows in result set" {
 			return models.UserProfileOut{}, errs.ErrProfileNotFound
@@ -68,8 +166,102 @@ func (r *ProfileRepos) GetProfile(ctx context.Context, userID uuid.UUID) (models
 	return profile, nil
 }
 
-// UpdateProfile - частичное обновление профиля пользователя
-func (r *ProfileRepos) UpdateProfile(ctx context.Context, profile models.UserProfileUpdate) error {
+// UpdateProfile - частичное обновление профиля пользователя. Возвращает новую версию профиля
+func (r *ProfileRepos) UpdateProfile(ctx context.Context, profile models.UserProfileUpdate) (models.ProfileVersion, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Errorf("Error while starting transaction %v", err)
+		return models.ProfileVersion{}, errs.ErrUpdateUserProfile
+	}
+	defer tx.Rollback(ctx)
+
+	updated, err := updateProfileTx(ctx, tx, "user_id = $1 AND deleted_at IS NULL AND NOT skeleton", profile.UserID, profile)
+	if err != nil {
+		return models.ProfileVersion{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		logger.Errorf("Error while committing user-profile %v", err)
+		return models.ProfileVersion{}, errs.ErrUpdateUserProfile
+	}
+	logger.Infof("Updated user-profile %v", updated.ID)
+	return models.ProfileVersion{ProfileID: updated.ID, Version: updated.Version}, nil
+}
+
+// updateProfileTx - обновление профиля, найденного по условию condition с плейсхолдером $1 для arg,
+// с записью старых и новых значений в журнал. Если задана profile.IfMatch, профиль должен быть этой версии.
+// Возвращает id, user_id и новую версию профиля
+func updateProfileTx(ctx context.Context, tx pgx.Tx, condition string, arg uuid.UUID, profile models.UserProfileUpdate) (models.UserProfileOut, error) {
+	// Блокируем строку, чтобы старые значения в журнале соответствовали обновляемым
+	var before models.UserProfileOut
+	query := `SELECT id, user_id, first_name, last_name, city, version FROM user_profiles WHERE ` + condition + ` FOR UPDATE`
+	err := tx.QueryRow(ctx, query, arg).Scan(&before.ID, &before.UserID, &before.FirstName, &before.LastName, &before.City, &before.Version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.UserProfileOut{}, errs.ErrProfileNotFound
+		}
+		logger.Errorf("Error while getting user-profile %v", err)
+		return models.UserProfileOut{}, errs.ErrUpdateUserProfile
+	}
+	if !profile.IfMatch.Matches(before.ID, before.Version) {
+		return models.UserProfileOut{}, errs.ErrVersionConflict
+	}
+
+	updates, args := profileUpdateSet(profile)
+	if len(updates) == 0 {
+		return models.UserProfileOut{}, errs.ErrUpdateUserProfile
+	}
+	args = append(args, before.ID)
+
+	// Формируем SQL-запрос
+	query = fmt.Sprintf(`
+		UPDATE user_profiles
+		SET %s
+		WHERE id = $%d
+		RETURNING version`, strings.Join(updates, ", "), len(args))
+
+	// Выполняем запрос
+	var version int64
+	if err = tx.QueryRow(ctx, query, args...).Scan(&version); err != nil {
+		logger.Errorf("Error while updating user-profile %v", err)
+		return models.UserProfileOut{}, errs.ErrUpdateUserProfile
+	}
+
+	oldValues, newValues := changedFields(
+		profileFields{"first_name": before.FirstName, "last_name": before.LastName, "city": before.City},
+		requestedFields(profile))
+	err = recordProfileAudit(ctx, tx, models.ProfileAuditUpdate, before.ID, before.UserID, oldValues, newValues)
+	if err != nil {
+		return models.UserProfileOut{}, err
+	}
+
+	// Событие отправляется, только если значения действительно изменились
+	if len(newValues) > 0 {
+		after := events.ProfilePayload{
+			ProfileID: before.ID,
+			UserID:    before.UserID,
+			FirstName: before.FirstName,
+			LastName:  before.LastName,
+			City:      before.City,
+		}
+		if profile.FirstName != "" {
+			after.FirstName = profile.FirstName
+		}
+		if profile.LastName != "" {
+			after.LastName = profile.LastName
+		}
+		if profile.City != "" {
+			after.City = profile.City
+		}
+		if err = recordOutboxEvent(ctx, tx, events.ProfileUpdated, before.ID, before.UserID, after); err != nil {
+			return models.UserProfileOut{}, err
+		}
+	}
+	before.Version = version
+	return before, nil
+}
+
+// profileUpdateSet - выражения SET и их аргументы для переданных полей профиля, нумерация с $1
+func profileUpdateSet(profile models.UserProfileUpdate) ([]string, []interface{}) {
 	var updates []string
 	var args []interface{}
 	argID := 1
@@ -88,41 +280,335 @@ func (r *ProfileRepos) UpdateProfile(ctx context.Context, profile models.UserPro
 	if profile.City != "" {
 		updates = append(updates, fmt.Sprintf("city = $%d", argID))
 		args = append(args, profile.City)
-		argID++
 	}
+	return updates, args
+}
 
-	// Добавляем user_id в конец списка аргументов
-	args = append(args, profile.UserID)
+// DeleteProfile - удаление профиля пользователя. Профиль помечается удаленным и окончательно
+// удаляется вместе с корзиной и картами после окончания срока восстановления
+func (r *ProfileRepos) DeleteProfile(ctx context.Context, userID uuid.UUID, ifMatch models.ProfileVersion) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Errorf("Error while starting transaction %v", err)
+		return errs.ErrDeleteUserProfile
+	}
+	defer tx.Rollback(ctx)
 
-	// Формируем SQL-запрос
+	profileID, err := deleteProfileTx(ctx, tx, userID, ifMatch)
+	if err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		logger.Errorf("Error while committing user-profile deletion %v", err)
+		return errs.ErrDeleteUserProfile
+	}
+	logger.Infof("Deleted user-profile %v", profileID)
+	return nil
+}
+
+// deleteProfileTx - пометка профиля пользователя удаленным с записью в журнал и outbox.
+// Если задана ifMatch, профиль должен быть этой версии. Возвращает id профиля
+func deleteProfileTx(ctx context.Context, tx pgx.Tx, userID uuid.UUID, ifMatch models.ProfileVersion) (uuid.UUID, error) {
+	var profileID uuid.UUID
+	var currentVersion int64
+	var skeleton bool
+	query := `SELECT id, version, skeleton FROM user_profiles WHERE user_id = $1 AND deleted_at IS NULL FOR UPDATE`
+	err := tx.QueryRow(ctx, query, userID).Scan(&profileID, &currentVersion, &skeleton)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.UUID{}, errs.ErrProfileNotFound
+		}
+		logger.Errorf("error while deleting user-profile %v", err)
+		return uuid.UUID{}, errs.ErrDeleteUserProfile
+	}
+	if !ifMatch.Matches(profileID, currentVersion) {
+		return uuid.UUID{}, errs.ErrVersionConflict
+	}
+
+	if _, err = tx.Exec(ctx, `UPDATE user_profiles SET deleted_at = NOW() WHERE id = $1`, profileID); err != nil {
+		logger.Errorf("error while deleting user-profile %v", err)
+		return uuid.UUID{}, errs.ErrDeleteUserProfile
+	}
+
+	if err = recordProfileAudit(ctx, tx, models.ProfileAuditDelete, profileID, userID, nil, nil); err != nil {
+		return uuid.UUID{}, err
+	}
+	// О незаполненном профиле подписчики еще не знают
+	if skeleton {
+		return profileID, nil
+	}
+	err = recordOutboxEvent(ctx, tx, events.ProfileDeleted, profileID, userID, events.ProfilePayload{ProfileID: profileID, UserID: userID})
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	return profileID, nil
+}
+
+// RestoreProfile - восстановление удаленного профиля, если срок восстановления grace еще не истек
+func (r *ProfileRepos) RestoreProfile(ctx context.Context, userID uuid.UUID, grace time.Duration) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Errorf("Error while starting transaction %v", err)
+		return errs.ErrRestoreProfile
+	}
+	defer tx.Rollback(ctx)
+
+	restored := events.ProfilePayload{UserID: userID}
+	var skeleton bool
+	query := `
+		UPDATE user_profiles
+		SET deleted_at = NULL
+		WHERE user_id = $1 AND deleted_at IS NOT NULL AND deleted_at > NOW() - make_interval(secs => $2)
+		RETURNING id, first_name, last_name, city, skeleton`
+	err = tx.QueryRow(ctx, query, userID, grace.Seconds()).Scan(&restored.ProfileID, &restored.FirstName,
+		&restored.LastName, &restored.City, &skeleton)
+	if err == nil {
+		profileID := restored.ProfileID
+		if err = recordProfileAudit(ctx, tx, models.ProfileAuditRestore, profileID, userID, nil, nil); err != nil {
+			return err
+		}
+		if !skeleton {
+			if err = recordOutboxEvent(ctx, tx, events.ProfileRestored, profileID, userID, restored); err != nil {
+				return err
+			}
+		}
+		if err = tx.Commit(ctx); err != nil {
+			logger.Errorf("Error while committing user-profile restore %v", err)
+			return errs.ErrRestoreProfile
+		}
+		logger.Infof("Restored user-profile %v", profileID)
+		return nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		logger.Errorf("Error while restoring user-profile %v", err)
+		return errs.ErrRestoreProfile
+	}
+
+	// Разбираемся, почему восстановить не удалось
+	var deleted bool
+	query = `SELECT deleted_at IS NOT NULL FROM user_profiles WHERE user_id = $1`
+	err = r.db.QueryRow(ctx, query, userID).Scan(&deleted)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errs.ErrProfileNotFound
+		}
+		logger.Errorf("Error while restoring user-profile %v", err)
+		return errs.ErrRestoreProfile
+	}
+	if !deleted {
+		return errs.ErrProfileNotDeleted
+	}
+	return errs.ErrRestorePeriodExpired
+}
+
+// PurgeDeletedProfiles - окончательное удаление профилей, удаленных раньше чем grace назад,
+// вместе с журналом их изменений и значениями полей в журнале администратора.
+// Удаляет не больше limit профилей за вызов и возвращает их число
+func (r *ProfileRepos) PurgeDeletedProfiles(ctx context.Context, grace time.Duration, limit int) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Errorf("Error while starting transaction %v", err)
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		DELETE FROM user_profiles
+		WHERE id IN (
+			SELECT id FROM user_profiles
+			WHERE deleted_at IS NOT NULL AND erased_at IS NULL AND deleted_at <= NOW() - make_interval(secs => $1)
+			ORDER BY deleted_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id`
+	rows, err := tx.Query(ctx, query, grace.Seconds(), limit)
+	if err != nil {
+		logger.Errorf("Error while purging deleted user-profiles %v", err)
+		return 0, err
+	}
+	var profileIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err = rows.Scan(&id); err != nil {
+			rows.Close()
+			logger.Errorf("Error while scanning purged user-profile %v", err)
+			return 0, err
+		}
+		profileIDs = append(profileIDs, id)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		logger.Errorf("Error while purging deleted user-profiles %v", err)
+		return 0, err
+	}
+	if len(profileIDs) == 0 {
+		return 0, nil
+	}
+
+	// В журнале изменений старые и новые значения полей и адреса клиентов, он удаляется вместе с профилем
+	if _, err = tx.Exec(ctx, `DELETE FROM profile_audit WHERE profile_id = ANY($1)`, profileIDs); err != nil {
+		logger.Errorf("Error while deleting profile audit of purged user-profiles %v", err)
+		return 0, err
+	}
+	// Действия администраторов остаются в журнале, но без значений полей
+	if _, err = tx.Exec(ctx, `UPDATE admin_actions SET details = NULL WHERE profile_id = ANY($1)`, profileIDs); err != nil {
+		logger.Errorf("Error while clearing admin action details of purged user-profiles %v", err)
+		return 0, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		logger.Errorf("Error while committing purge of user-profiles %v", err)
+		return 0, err
+	}
+	return len(profileIDs), nil
+}
+
+// ListProfiles - страница профилей с фильтрами, keyset пагинация по (поле сортировки, id)
+func (r *ProfileRepos) ListProfiles(ctx context.Context, filter models.ProfileListFilter) (models.ProfileList, error) {
+	conditions := []string{"deleted_at IS NULL", "NOT skeleton"}
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.City != "" {
+		conditions = append(conditions, "city = "+arg(filter.City))
+	}
+	if filter.NamePrefix != "" {
+		prefix := arg(likePrefix(strings.ToLower(filter.NamePrefix)))
+		conditions = append(conditions, fmt.Sprintf("(LOWER(first_name) LIKE %s OR LOWER(last_name) LIKE %s)", prefix, prefix))
+	}
+	if !filter.CreatedFrom.IsZero() {
+		conditions = append(conditions, "created_at >= "+arg(filter.CreatedFrom))
+	}
+	if !filter.CreatedTo.IsZero() {
+		conditions = append(conditions, "created_at < "+arg(filter.CreatedTo))
+	}
+
+	// Поле сортировки подставляется в запрос только из белого списка
+	sortColumn := models.ProfileSortCreatedAt
+	if filter.Sort == models.ProfileSortLastName {
+		sortColumn = models.ProfileSortLastName
+	}
+	order, compare := "DESC", "<"
+	if filter.Order == "asc" {
+		order, compare = "ASC", ">"
+	}
+
+	if filter.Cursor != "" {
+		cursor, err := models.DecodeProfileCursor(filter.Cursor, sortColumn)
+		if err != nil {
+			return models.ProfileList{}, errs.ErrInvalidCursor
+		}
+		var value interface{} = cursor.CreatedAt
+		if sortColumn == models.ProfileSortLastName {
+			value = cursor.LastName
+		}
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s (%s, %s)", sortColumn, compare, arg(value), arg(cursor.ID)))
+	}
+
+	where := "WHERE " + strings.Join(conditions, " AND ")
+
+	// Берем на одну запись больше, чтобы понять, есть ли следующая страница
 	query := fmt.Sprintf(`
-		UPDATE user_profiles 
-		SET %s 
-		WHERE user_id = $%d`, strings.Join(updates, ", "), argID)
+		SELECT id, user_id, first_name, last_name, city, created_at, updated_at
+		FROM user_profiles
+		%s
+		ORDER BY %s %s, id %s
+		LIMIT %s`, where, sortColumn, order, order, arg(filter.Limit+1))
 
-	// Выполняем запрос
-	_, err := r.db.Exec(ctx, query, args...)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
-		logger.Errorf("Error while updating user-profile %v", err)
-		return errs.ErrUpdateUserProfile
+		logger.Errorf("Error while listing user-profiles %v", err)
+		return models.ProfileList{}, errs.ErrListProfiles
 	}
+	defer rows.Close()
 
-	return nil
+	list := models.ProfileList{Items: []models.UserProfileOut{}}
+	for rows.Next() {
+		var profile models.UserProfileOut
+		err = rows.Scan(&profile.ID, &profile.UserID, &profile.FirstName, &profile.LastName, &profile.City, &profile.CreatedAt, &profile.UpdatedAt)
+		if err != nil {
+			logger.Errorf("Error while scanning user-profile %v", err)
+			return models.ProfileList{}, errs.ErrListProfiles
+		}
+		list.Items = append(list.Items, profile)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Errorf("Error while reading user-profiles %v", err)
+		return models.ProfileList{}, errs.ErrListProfiles
+	}
+
+	if len(list.Items) > filter.Limit {
+		list.Items = list.Items[:filter.Limit]
+		last := list.Items[len(list.Items)-1]
+		list.NextCursor = models.ProfileCursor{
+			Sort:      sortColumn,
+			CreatedAt: last.CreatedAt,
+			LastName:  last.LastName,
+			ID:        last.ID,
+		}.Encode()
+	}
+	return list, nil
 }
 
-// DeleteProfile - удаление профиля пользователя
-func (r *ProfileRepos) DeleteProfile(ctx context.Context, userID uuid.UUID) error {
-	// проверяем наличие профиля
-	_, err := r.GetProfile(ctx, userID)
+// likePrefix - шаблон LIKE для поиска по префиксу, спецсимволы LIKE экранируются
+func likePrefix(prefix string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(prefix) + "%"
+}
+
+// searchSimilarityThreshold - минимальное совпадение для поиска, ниже порога pg_trgm по умолчанию (0.6),
+// чтобы находились фамилии с опечаткой в одной-двух буквах
+const searchSimilarityThreshold = 0.3
+
+// SearchProfiles - нечеткий поиск по имени, фамилии и городу с сортировкой по степени совпадения
+func (r *ProfileRepos) SearchProfiles(ctx context.Context, q string, limit int) ([]models.ProfileSearchResult, error) {
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
-		return err
+		logger.Errorf("Error while starting transaction %v", err)
+		return nil, errs.ErrSearchProfiles
 	}
+	defer tx.Rollback(ctx)
 
-	query := `DELETE FROM user_profiles WHERE user_id = $1`
-	_, err = r.db.Exec(ctx, query, userID)
+	// Порог действует только внутри транзакции, оператор <% использует индекс по search_text
+	_, err = tx.Exec(ctx, `SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)`,
+		fmt.Sprint(searchSimilarityThreshold))
 	if err != nil {
-		logger.Errorf("error while deleting user-profile %v", err)
-		return errs.ErrDeleteUserProfile
+		logger.Errorf("Error while setting search threshold %v", err)
+		return nil, errs.ErrSearchProfiles
 	}
-	return nil
+
+	query := `
+		SELECT id, user_id, first_name, last_name, city, created_at, updated_at,
+		       word_similarity($1, search_text) AS score
+		FROM user_profiles
+		WHERE $1 <% search_text AND deleted_at IS NULL AND NOT skeleton
+		ORDER BY score DESC, id
+		LIMIT $2`
+	rows, err := tx.Query(ctx, query, strings.ToLower(q), limit)
+	if err != nil {
+		logger.Errorf("Error while searching user-profiles %v", err)
+		return nil, errs.ErrSearchProfiles
+	}
+	defer rows.Close()
+
+	results := []models.ProfileSearchResult{}
+	for rows.Next() {
+		var result models.ProfileSearchResult
+		err = rows.Scan(&result.ID, &result.UserID, &result.FirstName, &result.LastName, &result.City,
+			&result.CreatedAt, &result.UpdatedAt, &result.Score)
+		if err != nil {
+			logger.Errorf("Error while scanning user-profile %v", err)
+			return nil, errs.ErrSearchProfiles
+		}
+		results = append(results, result)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Errorf("Error while reading user-profiles %v", err)
+		return nil, errs.ErrSearchProfiles
+	}
+	return results, nil
 }
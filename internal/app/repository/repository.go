@@ -2,27 +2,126 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"service-user/internal/app/models"
+	"service-user/internal/app/vault"
 )
 
+// querier - общие методы пула и транзакции, чтобы одно чтение можно было выполнить в любом из них
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 // ProfileRepository - интерфейс репозитория для работы с профилем пользователя
 type ProfileRepository interface {
 	CreateProfile(ctx context.Context, profile models.UserProfileInput) (uuid.UUID, error)
 	GetProfile(ctx context.Context, userID uuid.UUID) (models.UserProfileOut, error)
-	UpdateProfile(ctx context.Context, profile models.UserProfileUpdate) error
-	DeleteProfile(ctx context.Context, userID uuid.UUID) error
+	UpdateProfile(ctx context.Context, profile models.UserProfileUpdate) (models.ProfileVersion, error)
+	DeleteProfile(ctx context.Context, userID uuid.UUID, ifMatch models.ProfileVersion) error
+	RestoreProfile(ctx context.Context, userID uuid.UUID, grace time.Duration) error
+	PurgeDeletedProfiles(ctx context.Context, grace time.Duration, limit int) (int, error)
+	ListProfiles(ctx context.Context, filter models.ProfileListFilter) (models.ProfileList, error)
+	SearchProfiles(ctx context.Context, q string, limit int) ([]models.ProfileSearchResult, error)
+	GetProfileHistory(ctx context.Context, userID uuid.UUID) ([]models.ProfileAuditRecord, error)
+}
+
+// CartRepository - интерфейс репозитория для работы с корзиной пользователя
+type CartRepository interface {
+	GetCart(ctx context.Context, userID uuid.UUID) (models.CartOut, error)
+	AddItem(ctx context.Context, userID uuid.UUID, item models.CartItemInput, price float64) (uuid.UUID, error)
+	UpdateItemQuantity(ctx context.Context, userID uuid.UUID, itemID uuid.UUID, quantity int) error
+	RemoveItem(ctx context.Context, userID uuid.UUID, itemID uuid.UUID) error
+	ClearCart(ctx context.Context, userID uuid.UUID) error
+}
+
+// CardRepository - интерфейс репозитория для работы с банковскими картами пользователя
+type CardRepository interface {
+	CreateCard(ctx context.Context, userID uuid.UUID, card models.UserBankCard) (uuid.UUID, error)
+	GetCards(ctx context.Context, userID uuid.UUID) ([]models.UserBankCardOut, error)
+	GetCard(ctx context.Context, userID uuid.UUID, cardID uuid.UUID) (models.UserBankCardOut, error)
+	UpdateCard(ctx context.Context, userID uuid.UUID, cardID uuid.UUID, card models.UserBankCardUpdate) error
+	DeleteCard(ctx context.Context, userID uuid.UUID, cardID uuid.UUID) error
+	SetDefaultCard(ctx context.Context, userID uuid.UUID, cardID uuid.UUID) error
+	NotifyExpiringCards(ctx context.Context, within time.Duration, limit int) (int, error)
+}
+
+// RevocationRepository - интерфейс репозитория списка отзыва токенов
+type RevocationRepository interface {
+	RevokeToken(ctx context.Context, tokenID string, userID uuid.UUID, expiresAt time.Time) error
+	RevokeUserTokens(ctx context.Context, userID uuid.UUID, before time.Time) error
+	GetRevocations(ctx context.Context, maxTokenLifetime time.Duration) (models.Revocations, error)
+	DeleteExpiredRevocations(ctx context.Context, maxTokenLifetime time.Duration) error
+}
+
+// AdminRepository - интерфейс репозитория действий администратора с профилями пользователей
+type AdminRepository interface {
+	AdminGetProfile(ctx context.Context, adminID uuid.UUID, ref models.ProfileRef) (models.UserProfileOut, error)
+	AdminUpdateProfile(ctx context.Context, adminID uuid.UUID, ref models.ProfileRef, profile models.UserProfileUpdate) error
+	AdminDeleteProfile(ctx context.Context, adminID uuid.UUID, ref models.ProfileRef) error
+	GetProfileAdminActions(ctx context.Context, userID uuid.UUID) ([]models.AuditRecord, error)
+}
+
+// ErasureRepository - интерфейс репозитория стирания персональных данных
+type ErasureRepository interface {
+	EraseProfile(ctx context.Context, ref models.ProfileRef, adminID uuid.UUID) (uuid.UUID, error)
+}
+
+// ExportRepository - интерфейс репозитория выгрузки персональных данных
+type ExportRepository interface {
+	ExportPersonalData(ctx context.Context, userID uuid.UUID) (models.PersonalDataExport, error)
+}
+
+// OutboxRepository - интерфейс репозитория доменных событий, ожидающих публикации
+type OutboxRepository interface {
+	ClaimOutboxEvents(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error)
+	MarkOutboxEventPublished(ctx context.Context, eventID uuid.UUID) error
+	RetryOutboxEvent(ctx context.Context, eventID uuid.UUID, delay time.Duration, reason string) error
+}
+
+// UserEventsRepository - интерфейс репозитория обработки событий сервиса авторизации
+type UserEventsRepository interface {
+	CreateSkeletonProfile(ctx context.Context, event models.UserLifecycleEvent) error
+	DeleteProfileByEvent(ctx context.Context, event models.UserLifecycleEvent) error
+}
+
+// IdempotencyRepository - интерфейс репозитория ответов на запросы с Idempotency-Key
+type IdempotencyRepository interface {
+	ReserveIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, reservation uuid.UUID, requestHash string, lease time.Duration) (*models.IdempotencyRecord, error)
+	SaveIdempotentResponse(ctx context.Context, userID uuid.UUID, key string, reservation uuid.UUID, response models.IdempotentResponse, ttl time.Duration) error
+	ReleaseIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, reservation uuid.UUID) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int, error)
 }
 
 type Repository struct {
 	ProfileRepository
+	CartRepository
+	CardRepository
+	RevocationRepository
+	AdminRepository
+	ErasureRepository
+	ExportRepository
+	OutboxRepository
+	UserEventsRepository
+	IdempotencyRepository
 }
 
-func NewRepository(db *pgxpool.Pool) *Repository {
+func NewRepository(db *pgxpool.Pool, cardVault vault.Vault, tombstoneKey []byte) *Repository {
 	return &Repository{
-		ProfileRepository: NewProfileRepository(db),
+		ProfileRepository:     NewProfileRepository(db, tombstoneKey),
+		CartRepository:        NewCartRepository(db),
+		CardRepository:        NewCardRepository(db, cardVault),
+		RevocationRepository:  NewRevocationRepository(db),
+		AdminRepository:       NewAdminRepository(db),
+		ErasureRepository:     NewErasureRepository(db, tombstoneKey),
+		ExportRepository:      NewExportRepository(db),
+		OutboxRepository:      NewOutboxRepository(db),
+		UserEventsRepository:  NewUserEventsRepository(db, tombstoneKey),
+		IdempotencyRepository: NewIdempotencyRepository(db),
 	}
 }
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	logger "github.com/sirupsen/logrus"
+
+	"service-user/internal/app/errs"
+	"service-user/internal/app/models"
+)
+
+// RevocationRepos - репозиторий списка отзыва токенов
+type RevocationRepos struct {
+	db *pgxpool.Pool
+}
+
+// NewRevocationRepository - конструктор репозитория списка отзыва
+func NewRevocationRepository(db *pgxpool.Pool) *RevocationRepos {
+	return &RevocationRepos{db: db}
+}
+
+// RevokeToken - отзыв одного токена по jti
+func (r *RevocationRepos) RevokeToken(ctx context.Context, tokenID string, userID uuid.UUID, expiresAt time.Time) error {
+	query := `
+		INSERT INTO revoked_tokens (jti, user_id, expires_at)
+		VALUES ($1, NULLIF($2, '00000000-0000-0000-0000-000000000000'::uuid), $3)
+		ON CONFLICT (jti) DO NOTHING`
+	_, err := r.db.Exec(ctx, query, tokenID, userID, expiresAt)
+	if err != nil {
+		logger.Errorf("Error while revoking token %v", err)
+		return errs.ErrRevokeToken
+	}
+	logger.Infof("Revoked token %s", tokenID)
+	return nil
+}
+
+// RevokeUserTokens - отзыв всех токенов пользователя, выпущенных раньше before
+func (r *RevocationRepos) RevokeUserTokens(ctx context.Context, userID uuid.UUID, before time.Time) error {
+	query := `
+		INSERT INTO revoked_user_tokens (user_id, revoked_before)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET revoked_before = GREATEST(revoked_user_tokens.revoked_before, EXCLUDED.revoked_before)`
+	_, err := r.db.Exec(ctx, query, userID, before)
+	if err != nil {
+		logger.Errorf("Error while revoking user tokens %v", err)
+		return errs.ErrRevokeToken
+	}
+	logger.Infof("Revoked tokens of user %v issued before %v", userID, before)
+	return nil
+}
+
+// GetRevocations - действующий список отзыва, истекшие токены в него не попадают. Отзыв всех токенов
+// пользователя действует maxTokenLifetime: выпущенные до него токены к этому времени истекли
+func (r *RevocationRepos) GetRevocations(ctx context.Context, maxTokenLifetime time.Duration) (models.Revocations, error) {
+	revocations := models.Revocations{
+		Tokens: map[string]time.Time{},
+		Users:  map[uuid.UUID]time.Time{},
+	}
+
+	rows, err := r.db.Query(ctx, `SELECT jti, expires_at FROM revoked_tokens WHERE expires_at > NOW()`)
+	if err != nil {
+		logger.Errorf("Error while getting revoked tokens %v", err)
+		return models.Revocations{}, errs.ErrGetRevocations
+	}
+	for rows.Next() {
+		var tokenID string
+		var expiresAt time.Time
+		if err = rows.Scan(&tokenID, &expiresAt); err != nil {
+			rows.Close()
+			logger.Errorf("Error while scanning revoked token %v", err)
+			return models.Revocations{}, errs.ErrGetRevocations
+		}
+		revocations.Tokens[tokenID] = expiresAt
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		logger.Errorf("Error while reading revoked tokens %v", err)
+		return models.Revocations{}, errs.ErrGetRevocations
+	}
+
+	query := `SELECT user_id, revoked_before FROM revoked_user_tokens WHERE revoked_before > NOW() - make_interval(secs => $1)`
+	rows, err = r.db.Query(ctx, query, maxTokenLifetime.Seconds())
+	if err != nil {
+		logger.Errorf("Error while getting revoked user tokens %v", err)
+		return models.Revocations{}, errs.ErrGetRevocations
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var userID uuid.UUID
+		var before time.Time
+		if err = rows.Scan(&userID, &before); err != nil {
+			logger.Errorf("Error while scanning revoked user tokens %v", err)
+			return models.Revocations{}, errs.ErrGetRevocations
+		}
+		revocations.Users[userID] = before
+	}
+	if err = rows.Err(); err != nil {
+		logger.Errorf("Error while reading revoked user tokens %v", err)
+		return models.Revocations{}, errs.ErrGetRevocations
+	}
+	return revocations, nil
+}
+
+// DeleteExpiredRevocations - удаление отзывов токенов, срок действия которых уже истек, и отзывов всех
+// токенов пользователя старше maxTokenLifetime
+func (r *RevocationRepos) DeleteExpiredRevocations(ctx context.Context, maxTokenLifetime time.Duration) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM revoked_tokens WHERE expires_at <= NOW()`)
+	if err != nil {
+		logger.Errorf("Error while deleting expired revocations %v", err)
+		return err
+	}
+	if tag.RowsAffected() > 0 {
+		logger.Debugf("Deleted %d expired revocations", tag.RowsAffected())
+	}
+
+	query := `DELETE FROM revoked_user_tokens WHERE revoked_before <= NOW() - make_interval(secs => $1)`
+	tag, err = r.db.Exec(ctx, query, maxTokenLifetime.Seconds())
+	if err != nil {
+		logger.Errorf("Error while deleting expired user revocations %v", err)
+		return err
+	}
+	if tag.RowsAffected() > 0 {
+		logger.Debugf("Deleted %d expired user revocations", tag.RowsAffected())
+	}
+	return nil
+}
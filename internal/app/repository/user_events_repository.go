@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	logger "github.com/sirupsen/logrus"
+
+	"service-user/internal/app/errs"
+	"service-user/internal/app/models"
+)
+
+// UserEventsRepos - репозиторий обработки событий сервиса авторизации
+type UserEventsRepos struct {
+	db           *pgxpool.Pool
+	tombstoneKey []byte
+}
+
+// NewUserEventsRepository - конструктор репозитория событий сервиса авторизации,
+// tombstoneKey - ключ HMAC отметок о стертых пользователях
+func NewUserEventsRepository(db *pgxpool.Pool, tombstoneKey []byte) *UserEventsRepos {
+	return &UserEventsRepos{db: db, tombstoneKey: tombstoneKey}
+}
+
+// CreateSkeletonProfile - создание пустого профиля по событию регистрации. Если у пользователя
+// уже есть профиль, в том числе удаленный, он не меняется. Повторное событие пропускается
+func (r *UserEventsRepos) CreateSkeletonProfile(ctx context.Context, event models.UserLifecycleEvent) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Errorf("Error while starting transaction %v", err)
+		return errs.ErrProcessEvent
+	}
+	defer tx.Rollback(ctx)
+
+	if first, err := markEventProcessed(ctx, tx, event); err != nil || !first {
+		return err
+	}
+
+	userID := event.Payload.UserID
+	query := `
+		INSERT INTO user_profiles (user_id, first_name, last_name, city, skeleton, recreated_after_erasure)
+		VALUES ($1, $2, $3, '', TRUE, EXISTS (SELECT 1 FROM erasure_tombstones WHERE user_id_hash = $4))
+		ON CONFLICT (user_id) DO NOTHING
+		RETURNING id`
+	var profileID uuid.UUID
+	err = tx.QueryRow(ctx, query, userID, event.Payload.FirstName, event.Payload.LastName, tombstoneHash(r.tombstoneKey, userID)).Scan(&profileID)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		logger.Infof("User-profile of registered user %v already exists", userID)
+	case err != nil:
+		logger.Errorf("Error while inserting skeleton user-profile %v", err)
+		return errs.ErrProcessEvent
+	default:
+		// profile.created публикуется, когда пользователь заполнит профиль
+		newValues := profileFields{"first_name": event.Payload.FirstName, "last_name": event.Payload.LastName}
+		if err = recordProfileAudit(ctx, tx, models.ProfileAuditCreate, profileID, userID, nil, newValues); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		logger.Errorf("Error while committing event %v: %v", event.ID, err)
+		return errs.ErrProcessEvent
+	}
+	return nil
+}
+
+// DeleteProfileByEvent - удаление профиля по событию удаления пользователя, профиль можно
+// восстановить в течение срока восстановления. Повторное событие пропускается
+func (r *UserEventsRepos) DeleteProfileByEvent(ctx context.Context, event models.UserLifecycleEvent) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Errorf("Error while starting transaction %v", err)
+		return errs.ErrProcessEvent
+	}
+	defer tx.Rollback(ctx)
+
+	if first, err := markEventProcessed(ctx, tx, event); err != nil || !first {
+		return err
+	}
+
+	_, err = deleteProfileTx(ctx, tx, event.Payload.UserID, models.ProfileVersion{})
+	if err != nil && !errors.Is(err, errs.ErrProfileNotFound) {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		logger.Errorf("Error while committing event %v: %v", event.ID, err)
+		return errs.ErrProcessEvent
+	}
+	return nil
+}
+
+// markEventProcessed - отметка об обработке события в транзакции обработки.
+// Возвращает false, если событие уже обработано
+func markEventProcessed(ctx context.Context, tx pgx.Tx, event models.UserLifecycleEvent) (bool, error) {
+	query := `INSERT INTO processed_events (event_id, event_type, user_id) VALUES ($1, $2, $3) ON CONFLICT (event_id) DO NOTHING`
+	tag, err := tx.Exec(ctx, query, event.ID, event.Type, event.Payload.UserID)
+	if err != nil {
+		logger.Errorf("Error while marking event %v processed %v", event.ID, err)
+		return false, errs.ErrProcessEvent
+	}
+	if tag.RowsAffected() == 0 {
+		logger.Infof("Event %s %v is already processed", event.Type, event.ID)
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"service-user/internal/app/models"
+	"service-user/internal/app/repository"
+)
+
+type Admin struct {
+	repo *repository.Repository
+}
+
+func NewServiceAdmin(repo *repository.Repository) *Admin {
+	return &Admin{repo}
+}
+
+func (a *Admin) AdminGetProfile(ctx context.Context, adminID uuid.UUID, ref models.ProfileRef) (models.UserProfileOut, error) {
+	profile, err := a.repo.AdminGetProfile(ctx, adminID, ref)
+	if err != nil {
+		return models.UserProfileOut{}, err
+	}
+	return profile, nil
+}
+
+func (a *Admin) AdminUpdateProfile(ctx context.Context, adminID uuid.UUID, ref models.ProfileRef, profile models.UserProfileUpdate) error {
+	err := a.repo.AdminUpdateProfile(ctx, adminID, ref, profile)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (a *Admin) AdminDeleteProfile(ctx context.Context, adminID uuid.UUID, ref models.ProfileRef) error {
+	err := a.repo.AdminDeleteProfile(ctx, adminID, ref)
+	if err != nil {
+		return err
+	}
+	return nil
+}
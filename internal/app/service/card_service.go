@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"service-user/internal/app/models"
+	"service-user/internal/app/repository"
+)
+
+type Card struct {
+	repo *repository.Repository
+}
+
+func NewServiceCard(repo *repository.Repository) *Card {
+	return &Card{repo}
+}
+
+func (c *Card) CreateCard(ctx context.Context, userID uuid.UUID, card models.UserBankCard) (uuid.UUID, error) {
+	id, err := c.repo.CreateCard(ctx, userID, card)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	return id, nil
+}
+
+func (c *Card) GetCards(ctx context.Context, userID uuid.UUID) ([]models.UserBankCardOut, error) {
+	_, err := c.repo.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	cards, err := c.repo.GetCards(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return cards, nil
+}
+
+func (c *Card) GetCard(ctx context.Context, userID uuid.UUID, cardID uuid.UUID) (models.UserBankCardOut, error) {
+	card, err := c.repo.GetCard(ctx, userID, cardID)
+	if err != nil {
+		return models.UserBankCardOut{}, err
+	}
+	return card, nil
+}
+
+func (c *Card) UpdateCard(ctx context.Context, userID uuid.UUID, cardID uuid.UUID, card models.UserBankCardUpdate) error {
+	_, err := c.GetCard(ctx, userID, cardID)
+	if err != nil {
+		return err
+	}
+
+	err = c.repo.UpdateCard(ctx, userID, cardID, card)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *Card) DeleteCard(ctx context.Context, userID uuid.UUID, cardID uuid.UUID) error {
+	err := c.repo.DeleteCard(ctx, userID, cardID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *Card) SetDefaultCard(ctx context.Context, userID uuid.UUID, cardID uuid.UUID) error {
+	err := c.repo.SetDefaultCard(ctx, userID, cardID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
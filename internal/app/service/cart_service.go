@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"service-user/internal/app/catalog"
+	"service-user/internal/app/models"
+	"service-user/internal/app/repository"
+)
+
+type Cart struct {
+	repo    *repository.Repository
+	catalog catalog.Catalog
+}
+
+func NewServiceCart(repo *repository.Repository, catalog catalog.Catalog) *Cart {
+	return &Cart{
+		repo:    repo,
+		catalog: catalog,
+	}
+}
+
+func (c *Cart) GetCart(ctx context.Context, userID uuid.UUID) (models.CartOut, error) {
+	cart, err := c.repo.GetCart(ctx, userID)
+	if err != nil {
+		return models.CartOut{}, err
+	}
+	return cart, nil
+}
+
+func (c *Cart) AddItem(ctx context.Context, userID uuid.UUID, item models.CartItemInput) (uuid.UUID, error) {
+	price, err := c.catalog.ProductPrice(ctx, item.ProductID)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+
+	id, err := c.repo.AddItem(ctx, userID, item, price)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	return id, nil
+}
+
+func (c *Cart) UpdateItemQuantity(ctx context.Context, userID uuid.UUID, itemID uuid.UUID, quantity int) error {
+	err := c.repo.UpdateItemQuantity(ctx, userID, itemID, quantity)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *Cart) RemoveItem(ctx context.Context, userID uuid.UUID, itemID uuid.UUID) error {
+	err := c.repo.RemoveItem(ctx, userID, itemID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *Cart) ClearCart(ctx context.Context, userID uuid.UUID) error {
+	err := c.repo.ClearCart(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
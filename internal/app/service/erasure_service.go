@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"service-user/internal/app/models"
+	"service-user/internal/app/repository"
+)
+
+type Erasure struct {
+	repo *repository.Repository
+}
+
+func NewServiceErasure(repo *repository.Repository) *Erasure {
+	return &Erasure{repo}
+}
+
+// EraseProfile - стирание персональных данных по запросу самого пользователя
+func (e *Erasure) EraseProfile(ctx context.Context, userID uuid.UUID) error {
+	_, err := e.repo.EraseProfile(ctx, models.ProfileRef{UserID: userID}, uuid.Nil)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// AdminEraseProfile - стирание персональных данных администратором, действие записывается в журнал
+func (e *Erasure) AdminEraseProfile(ctx context.Context, adminID uuid.UUID, ref models.ProfileRef) error {
+	_, err := e.repo.EraseProfile(ctx, ref, adminID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"service-user/internal/app/errs"
+	"service-user/internal/app/models"
+	"service-user/internal/app/repository"
+	"service-user/internal/configs"
+)
+
+type Idempotency struct {
+	repo  *repository.Repository
+	ttl   time.Duration // сколько хранится ответ на запрос с Idempotency-Key
+	lease time.Duration // сколько ключ занят выполняющимся запросом
+}
+
+func NewServiceIdempotency(repo *repository.Repository, cfg *configs.IdempotencyConfig) *Idempotency {
+	return &Idempotency{
+		repo:  repo,
+		ttl:   cfg.TTL,
+		lease: cfg.Lease,
+	}
+}
+
+// ReserveIdempotencyKey - занимает ключ для запроса с токеном reservation. Возвращает сохраненный ответ,
+// если запрос с этим ключом уже выполнен, или nil, если запрос нужно выполнить
+func (i *Idempotency) ReserveIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, reservation uuid.UUID, requestHash string) (*models.IdempotentResponse, error) {
+	record, err := i.repo.ReserveIdempotencyKey(ctx, userID, key, reservation, requestHash, i.lease)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, nil
+	}
+	if record.RequestHash != requestHash {
+		return nil, errs.ErrIdempotencyKeyReused
+	}
+	if record.Response == nil {
+		return nil, errs.ErrIdempotencyKeyInProgress
+	}
+	return record.Response, nil
+}
+
+func (i *Idempotency) SaveIdempotentResponse(ctx context.Context, userID uuid.UUID, key string, reservation uuid.UUID, response models.IdempotentResponse) error {
+	err := i.repo.SaveIdempotentResponse(ctx, userID, key, reservation, response, i.ttl)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (i *Idempotency) ReleaseIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, reservation uuid.UUID) error {
+	err := i.repo.ReleaseIdempotencyKey(ctx, userID, key, reservation)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (i *Idempotency) DeleteExpiredIdempotencyKeys(ctx context.Context) (int, error) {
+	deleted, err := i.repo.DeleteExpiredIdempotencyKeys(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
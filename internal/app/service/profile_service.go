@@ -2,19 +2,28 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
+	"service-user/internal/app/errs"
 	"service-user/internal/app/models"
 	"service-user/internal/app/repository"
+	"service-user/internal/configs"
 )
 
 type Profile struct {
-	repo *repository.Repository
+	repo           *repository.Repository
+	deleteGrace    time.Duration // срок, в течение которого удаленный профиль можно восстановить
+	requireIfMatch bool          // изменение и удаление профиля только с версией из If-Match
 }
 
-func NewServiceProfile(repo *repository.Repository) *Profile {
-	return &Profile{repo}
+func NewServiceProfile(repo *repository.Repository, cfg *configs.ProfilesConfig) *Profile {
+	return &Profile{
+		repo:           repo,
+		deleteGrace:    cfg.DeleteGracePeriod,
+		requireIfMatch: cfg.RequireIfMatch,
+	}
 }
 
 func (p *Profile) CreateProfile(ctx context.Context, profile models.UserProfileInput) (uuid.UUID, error) {
@@ -33,23 +42,77 @@ func (p *Profile) GetProfile(ctx context.Context, userID uuid.UUID) (models.User
 	return profile, nil
 }
 
-func (p *Profile) UpdateProfile(ctx context.Context, profile models.UserProfileUpdate) error {
+func (p *Profile) UpdateProfile(ctx context.Context, profile models.UserProfileUpdate) (models.ProfileVersion, error) {
+	if p.requireIfMatch && profile.IfMatch.Version == models.NoVersion {
+		return models.ProfileVersion{}, errs.ErrPreconditionRequired
+	}
+
 	_, err := p.GetProfile(ctx, profile.UserID)
 	if err != nil {
-		return err
+		return models.ProfileVersion{}, err
+	}
+
+	version, err := p.repo.UpdateProfile(ctx, profile)
+	if err != nil {
+		return models.ProfileVersion{}, err
+	}
+	return version, nil
+}
+
+func (p *Profile) DeleteProfile(ctx context.Context, userID uuid.UUID, ifMatch models.ProfileVersion) error {
+	if p.requireIfMatch && ifMatch.Version == models.NoVersion {
+		return errs.ErrPreconditionRequired
 	}
 
-	err = p.repo.UpdateProfile(ctx, profile)
+	err := p.repo.DeleteProfile(ctx, userID, ifMatch)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (p *Profile) DeleteProfile(ctx context.Context, userID uuid.UUID) error {
-	err := p.repo.DeleteProfile(ctx, userID)
+func (p *Profile) RestoreProfile(ctx context.Context, userID uuid.UUID) error {
+	err := p.repo.RestoreProfile(ctx, userID, p.deleteGrace)
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+// ExportPersonalData - собирает все данные пользователя из одного снимка базы
+func (p *Profile) ExportPersonalData(ctx context.Context, userID uuid.UUID) (models.PersonalDataExport, error) {
+	export, err := p.repo.ExportPersonalData(ctx, userID)
+	if err != nil {
+		return models.PersonalDataExport{}, err
+	}
+	export.GeneratedAt = time.Now().UTC()
+	return export, nil
+}
+
+func (p *Profile) GetProfileHistory(ctx context.Context, userID uuid.UUID) ([]models.ProfileAuditRecord, error) {
+	history, err := p.repo.GetProfileHistory(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+func (p *Profile) ListProfiles(ctx context.Context, filter models.ProfileListFilter) (models.ProfileList, error) {
+	filter.SetDefaults()
+	list, err := p.repo.ListProfiles(ctx, filter)
+	if err != nil {
+		return models.ProfileList{}, err
+	}
+	return list, nil
+}
+
+func (p *Profile) SearchProfiles(ctx context.Context, q string, limit int) ([]models.ProfileSearchResult, error) {
+	if limit == 0 {
+		limit = models.DefaultProfileSearchLimit
+	}
+	results, err := p.repo.SearchProfiles(ctx, q, limit)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+
+	"service-user/internal/app/models"
+	"service-user/internal/app/repository"
+	"service-user/internal/configs"
+)
+
+// Revocation - список отзыва токенов. Проверка выполняется по копии в памяти,
+// которая обновляется из базы с интервалом и сразу после отзыва на этом экземпляре
+type Revocation struct {
+	repo *repository.Repository
+	// сколько действует отзыв всех токенов пользователя: максимальное время жизни токена с допуском часов
+	userRevocationTTL time.Duration
+
+	mu          sync.RWMutex
+	revocations models.Revocations
+	loaded      bool
+}
+
+func NewServiceRevocation(repo *repository.Repository, cfg *configs.AuthConfig) *Revocation {
+	return &Revocation{
+		repo:              repo,
+		userRevocationTTL: cfg.MaxTokenLifetime + cfg.Leeway,
+	}
+}
+
+func (r *Revocation) RevokeToken(ctx context.Context, input models.TokenRevocationInput) error {
+	if input.TokenID != "" {
+		if err := r.repo.RevokeToken(ctx, input.TokenID, input.UserID, input.ExpiresAt); err != nil {
+			return err
+		}
+	} else {
+		if err := r.repo.RevokeUserTokens(ctx, input.UserID, time.Now()); err != nil {
+			return err
+		}
+	}
+	// Отзыв уже сохранен, остальные экземпляры и этот подхватят его при следующем обновлении
+	if err := r.RefreshRevocations(ctx); err != nil {
+		logger.Warnf("Failed to refresh revoked tokens after revocation: %v", err)
+	}
+	return nil
+}
+
+// IsRevoked - отозван ли токен. Пока список ни разу не загружен, отозванными считаются все токены
+func (r *Revocation) IsRevoked(claims models.AccessClaims) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if !r.loaded {
+		return true
+	}
+	return r.revocations.IsRevoked(claims)
+}
+
+// RefreshRevocations - загрузка списка отзыва из базы. При ошибке остается предыдущая копия
+func (r *Revocation) RefreshRevocations(ctx context.Context) error {
+	revocations, err := r.repo.GetRevocations(ctx, r.userRevocationTTL)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.revocations = revocations
+	r.loaded = true
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *Revocation) DeleteExpiredRevocations(ctx context.Context) error {
+	return r.repo.DeleteExpiredRevocations(ctx, r.userRevocationTTL)
+}
@@ -5,23 +5,90 @@ import (
 
 	"github.com/google/uuid"
 
+	"service-user/internal/app/catalog"
 	"service-user/internal/app/models"
 	"service-user/internal/app/repository"
+	"service-user/internal/configs"
 )
 
 type ProfileService interface {
 	CreateProfile(ctx context.Context, profile models.UserProfileInput) (uuid.UUID, error)
 	GetProfile(ctx context.Context, userID uuid.UUID) (models.UserProfileOut, error)
-	UpdateProfile(ctx context.Context, profile models.UserProfileUpdate) error
-	DeleteProfile(ctx context.Context, userID uuid.UUID) error
+	UpdateProfile(ctx context.Context, profile models.UserProfileUpdate) (models.ProfileVersion, error)
+	DeleteProfile(ctx context.Context, userID uuid.UUID, ifMatch models.ProfileVersion) error
+	RestoreProfile(ctx context.Context, userID uuid.UUID) error
+	ExportPersonalData(ctx context.Context, userID uuid.UUID) (models.PersonalDataExport, error)
+	ListProfiles(ctx context.Context, filter models.ProfileListFilter) (models.ProfileList, error)
+	SearchProfiles(ctx context.Context, q string, limit int) ([]models.ProfileSearchResult, error)
+	GetProfileHistory(ctx context.Context, userID uuid.UUID) ([]models.ProfileAuditRecord, error)
+}
+
+type CartService interface {
+	GetCart(ctx context.Context, userID uuid.UUID) (models.CartOut, error)
+	AddItem(ctx context.Context, userID uuid.UUID, item models.CartItemInput) (uuid.UUID, error)
+	UpdateItemQuantity(ctx context.Context, userID uuid.UUID, itemID uuid.UUID, quantity int) error
+	RemoveItem(ctx context.Context, userID uuid.UUID, itemID uuid.UUID) error
+	ClearCart(ctx context.Context, userID uuid.UUID) error
+}
+
+type CardService interface {
+	CreateCard(ctx context.Context, userID uuid.UUID, card models.UserBankCard) (uuid.UUID, error)
+	GetCards(ctx context.Context, userID uuid.UUID) ([]models.UserBankCardOut, error)
+	GetCard(ctx context.Context, userID uuid.UUID, cardID uuid.UUID) (models.UserBankCardOut, error)
+	UpdateCard(ctx context.Context, userID uuid.UUID, cardID uuid.UUID, card models.UserBankCardUpdate) error
+	DeleteCard(ctx context.Context, userID uuid.UUID, cardID uuid.UUID) error
+	SetDefaultCard(ctx context.Context, userID uuid.UUID, cardID uuid.UUID) error
+}
+
+type RevocationService interface {
+	RevokeToken(ctx context.Context, input models.TokenRevocationInput) error
+	IsRevoked(claims models.AccessClaims) bool
+	RefreshRevocations(ctx context.Context) error
+	DeleteExpiredRevocations(ctx context.Context) error
+}
+
+type AdminService interface {
+	AdminGetProfile(ctx context.Context, adminID uuid.UUID, ref models.ProfileRef) (models.UserProfileOut, error)
+	AdminUpdateProfile(ctx context.Context, adminID uuid.UUID, ref models.ProfileRef, profile models.UserProfileUpdate) error
+	AdminDeleteProfile(ctx context.Context, adminID uuid.UUID, ref models.ProfileRef) error
+}
+
+type ErasureService interface {
+	EraseProfile(ctx context.Context, userID uuid.UUID) error
+	AdminEraseProfile(ctx context.Context, adminID uuid.UUID, ref models.ProfileRef) error
+}
+
+type UserEventsService interface {
+	HandleUserEvent(ctx context.Context, event models.UserLifecycleEvent) error
+}
+
+type IdempotencyService interface {
+	ReserveIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, reservation uuid.UUID, requestHash string) (*models.IdempotentResponse, error)
+	SaveIdempotentResponse(ctx context.Context, userID uuid.UUID, key string, reservation uuid.UUID, response models.IdempotentResponse) error
+	ReleaseIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, reservation uuid.UUID) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int, error)
 }
 
 type Service struct {
 	ProfileService
+	CartService
+	CardService
+	RevocationService
+	AdminService
+	ErasureService
+	UserEventsService
+	IdempotencyService
 }
 
-func NewService(repo *repository.Repository) *Service {
+func NewService(repo *repository.Repository, catalog catalog.Catalog, cfg *configs.Config) *Service {
 	return &Service{
-		ProfileService: NewServiceProfile(repo),
+		ProfileService:     NewServiceProfile(repo, &cfg.Profiles),
+		CartService:        NewServiceCart(repo, catalog),
+		CardService:        NewServiceCard(repo),
+		RevocationService:  NewServiceRevocation(repo, &cfg.Auth),
+		AdminService:       NewServiceAdmin(repo),
+		ErasureService:     NewServiceErasure(repo),
+		UserEventsService:  NewServiceUserEvents(repo),
+		IdempotencyService: NewServiceIdempotency(repo, &cfg.Idempotency),
 	}
 }
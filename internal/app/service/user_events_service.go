@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	logger "github.com/sirupsen/logrus"
+
+	"service-user/internal/app/errs"
+	"service-user/internal/app/models"
+	"service-user/internal/app/repository"
+)
+
+type UserEvents struct {
+	repo *repository.Repository
+}
+
+func NewServiceUserEvents(repo *repository.Repository) *UserEvents {
+	return &UserEvents{repo}
+}
+
+// HandleUserEvent - обработка события сервиса авторизации. Событие, не прошедшее проверку,
+// возвращается с ErrInvalidEvent, остальные ошибки временные и обработку нужно повторить
+func (u *UserEvents) HandleUserEvent(ctx context.Context, event models.UserLifecycleEvent) error {
+	if err := event.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", errs.ErrInvalidEvent, err)
+	}
+
+	switch event.Type {
+	case models.UserRegistered:
+		return u.repo.CreateSkeletonProfile(ctx, event)
+	case models.UserDeleted:
+		return u.repo.DeleteProfileByEvent(ctx, event)
+	default:
+		logger.Debugf("Skipping event %s %v", event.Type, event.ID)
+		return nil
+	}
+}
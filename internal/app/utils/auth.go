@@ -1,66 +1,170 @@
 package utils
 
 import (
-	"crypto/rsa"
+	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"slices"
 
 	"github.com/golang-jwt/jwt/v5"
 
 	logger "github.com/sirupsen/logrus"
 
 	"service-user/internal/app/errs"
+	"service-user/internal/configs"
 )
 
 type JWTManager struct {
-	publicKey *rsa.PublicKey
+	publicKeys     []verificationKey // локальные ключи, используются если JWKS недоступен
+	jwks           *JWKSCache
+	parser         *jwt.Parser
+	requiredClaims []string
 }
 
-// NewJWTManager загружает RSA-ключи и создает JWT-менеджер
-func NewJWTManager(publicKeyPath string) (*JWTManager, error) {
-	// Загружаем публичный ключ
-	publicKeyData, err := os.ReadFile(publicKeyPath)
+// NewJWTManager загружает локальные публичные ключи и, если включено, ключи из JWKS сервиса авторизации
+func NewJWTManager(cfg *configs.AuthConfig) (*JWTManager, error) {
+	algorithms, err := validateAlgorithms(cfg.Algorithms)
 	if err != nil {
-		logger.WithError(err).Error("failed to read public key")
-		return nil, fmt.Errorf("failed to read public key: %w", err)
+		logger.WithError(err).Error("invalid signing algorithms")
+		return nil, err
 	}
 
-	publicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicKeyData)
-	if err != nil {
-		logger.WithError(err).Error("failed to parse public key")
-		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	manager := &JWTManager{
+		parser:         jwt.NewParser(parserOptions(cfg, algorithms)...),
+		requiredClaims: cfg.RequiredClaims,
+	}
+
+	if cfg.PublicKey != "" {
+		// Загружаем публичные ключи (RSA, ECDSA, Ed25519)
+		publicKeyData, err := os.ReadFile(cfg.PublicKey)
+		if err != nil {
+			logger.WithError(err).Error("failed to read public key")
+			return nil, fmt.Errorf("failed to read public key: %w", err)
+		}
+
+		manager.publicKeys, err = parsePublicKeysPEM(publicKeyData)
+		if err != nil {
+			logger.WithError(err).Error("failed to parse public key")
+			return nil, fmt.Errorf("failed to parse public key: %w", err)
+		}
+	}
+
+	if cfg.Jwks.Enabled {
+		client := &http.Client{Timeout: cfg.Jwks.Timeout}
+		manager.jwks = NewJWKSCache(cfg.Jwks.Url, client, cfg.Jwks.RefreshInterval, cfg.Jwks.MinRefreshInterval)
+
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Jwks.Timeout)
+		defer cancel()
+		if err := manager.jwks.Refresh(ctx); err != nil {
+			logger.WithError(err).Warn("JWKS is unavailable, falling back to local public key")
+		}
 	}
 
-	return &JWTManager{
-		publicKey: publicKey,
-	}, nil
+	if len(manager.publicKeys) == 0 && manager.jwks == nil {
+		return nil, errors.New("neither public key nor JWKS is configured")
+	}
+	return manager, nil
+}
+
+// parserOptions - проверки, которые выполняет парсер помимо подписи
+func parserOptions(cfg *configs.AuthConfig, algorithms []string) []jwt.ParserOption {
+	options := []jwt.ParserOption{
+		// Алгоритм токена проверяется до выбора ключа, none и HMAC отклоняются всегда
+		jwt.WithValidMethods(algorithms),
+		jwt.WithLeeway(cfg.Leeway),
+		jwt.WithIssuedAt(),
+	}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+	if slices.Contains(cfg.RequiredClaims, "exp") {
+		options = append(options, jwt.WithExpirationRequired())
+	}
+	return options
+}
+
+// Run периодически обновляет ключи из JWKS, пока не отменен ctx
+func (j *JWTManager) Run(ctx context.Context) {
+	if j.jwks != nil {
+		j.jwks.Run(ctx)
+	}
 }
 
 // DecodeJWT парсит токен и проверяет его подпись публичным ключом
 func (j *JWTManager) DecodeJWT(tokenString string) (jwt.Claims, error) {
 	// Разбираем и проверяем подпись токена
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Проверяем, что используется алгоритм RS256
-		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-			logger.Errorf("Unexpected signing method: %v", token.Header["alg"])
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return j.publicKey, nil // Возвращаем публичный ключ для проверки подписи
-	})
+	token, err := j.parser.Parse(tokenString, j.verificationKey)
 
 	if err != nil {
-		if errors.Is(err, jwt.ErrTokenExpired) {
-			logger.Debugf("Error: token is expired: %v", err)
-			return nil, errs.ErrTokenExpired
-		}
 		logger.Debugf("Error parsing token: %v", err)
-		return nil, errs.ErrTokenInvalid
+		return nil, tokenError(err)
 	}
 
 	// Проверяем валидность токена
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errs.ErrTokenInvalid
+	}
+
+	for _, name := range j.requiredClaims {
+		if value, ok := claims[name]; !ok || value == nil || value == "" {
+			logger.Debugf("Error: token is missing claim %q", name)
+			return nil, errs.ErrTokenClaimMissing
+		}
+	}
+	return claims, nil
+}
+
+// tokenError переводит ошибку проверки токена в ошибку сервиса
+func tokenError(err error) error {
+	switch {
+	case errors.Is(err, jwt.ErrTokenRequiredClaimMissing):
+		return errs.ErrTokenClaimMissing
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return errs.ErrTokenExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return errs.ErrTokenNotYetValid
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		return errs.ErrTokenIssuerInvalid
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return errs.ErrTokenAudienceInvalid
+	default:
+		return errs.ErrTokenInvalid
+	}
+}
+
+// verificationKey выбирает ключ по kid из JWKS. Локальные ключи используются для токенов
+// без kid и когда JWKS недоступен. Ключ должен соответствовать алгоритму токена,
+// иначе подпись одним типом ключа можно было бы выдать за другой
+func (j *JWTManager) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	if j.jwks != nil && kid != "" {
+		key, err := j.jwks.Key(context.Background(), kid)
+		if err == nil {
+			if !keyMatchesMethod(key, token.Method) {
+				return nil, fmt.Errorf("key %q does not match signing method %s", kid, token.Method.Alg())
+			}
+			return key.key, nil
+		}
+		if j.jwks.Available() || len(j.publicKeys) == 0 {
+			return nil, fmt.Errorf("unknown key id %q: %w", kid, err)
+		}
+	}
+
+	var keys jwt.VerificationKeySet
+	for _, key := range j.publicKeys {
+		if keyMatchesMethod(key, token.Method) {
+			keys.Keys = append(keys.Keys, key.key)
+		}
+	}
+	if len(keys.Keys) == 0 {
+		return nil, fmt.Errorf("no local key for signing method %s", token.Method.Alg())
 	}
-	return nil, errs.ErrTokenInvalid
+	return keys, nil
 }
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"strconv"
+	"time"
+)
+
+// Платежные системы банковских карт
+const (
+	BrandVisa       = "Visa"
+	BrandMastercard = "Mastercard"
+	BrandMir        = "Mir"
+	BrandAmex       = "American Express"
+	BrandUnionPay   = "UnionPay"
+	BrandJCB        = "JCB"
+	BrandDiscover   = "Discover"
+	BrandMaestro    = "Maestro"
+	BrandUnknown    = "Unknown"
+)
+
+// cardBrandRange - диапазон префиксов номера (IIN) платежной системы
+type cardBrandRange struct {
+	brand  string
+	digits int // длина префикса
+	from   int
+	to     int
+}
+
+// Более специфичные диапазоны идут раньше: Mir 2200-2204 пересекается с Mastercard 2221-2720 только по первой цифре
+var cardBrandRanges = []cardBrandRange{
+	{BrandMir, 4, 2200, 2204},
+	{BrandMastercard, 4, 2221, 2720},
+	{BrandMastercard, 2, 51, 55},
+	{BrandAmex, 2, 34, 34},
+	{BrandAmex, 2, 37, 37},
+	{BrandJCB, 4, 3528, 3589},
+	{BrandDiscover, 4, 6011, 6011},
+	{BrandDiscover, 3, 644, 649},
+	{BrandDiscover, 2, 65, 65},
+	{BrandUnionPay, 2, 62, 62},
+	{BrandMaestro, 2, 50, 50},
+	{BrandMaestro, 2, 56, 69},
+	{BrandVisa, 1, 4, 4},
+}
+
+// CardBrand определяет платежную систему по номеру карты
+func CardBrand(number string) string {
+	for _, r := range cardBrandRanges {
+		if len(number) < r.digits {
+			continue
+		}
+		prefix, err := strconv.Atoi(number[:r.digits])
+		if err != nil {
+			return BrandUnknown
+		}
+		if prefix >= r.from && prefix <= r.to {
+			return r.brand
+		}
+	}
+	return BrandUnknown
+}
+
+// CardLast4 - последние 4 цифры номера карты
+func CardLast4(number string) string {
+	if len(number) < 4 {
+		return number
+	}
+	return number[len(number)-4:]
+}
+
+// LuhnValid проверяет контрольную сумму номера карты по алгоритму Луна
+func LuhnValid(number string) bool {
+	if number == "" {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if d < 0 || d > 9 {
+			return false
+		}
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// CardExpiresAt - момент, когда карта со сроком MM/YY перестает действовать (начало следующего месяца, UTC)
+func CardExpiresAt(expirationDate string) (time.Time, error) {
+	month, err := time.Parse("01/06", expirationDate)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return month.AddDate(0, 1, 0), nil
+}
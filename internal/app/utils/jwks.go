@@ -0,0 +1,234 @@
+package utils
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+var ErrJWKSKeyNotFound = errors.New("jwks: key not found")
+
+// jwk - ключ из JWKS документа (RFC 7517)
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwksDocument struct {
+	Keys []jwk `json:"keys"`
+}
+
+// JWKSCache - кэш публичных ключей сервиса авторизации, загружаемых из JWKS эндпоинта
+type JWKSCache struct {
+	url                string
+	client             *http.Client
+	refreshInterval    time.Duration // плановое обновление
+	minRefreshInterval time.Duration // не чаще этого обновляемся из-за неизвестного kid
+
+	refreshMu   sync.Mutex // один внеплановый запрос за раз, остальные ждут его результата
+	mu          sync.RWMutex
+	keys        map[string]verificationKey
+	available   bool // последняя загрузка была успешной
+	lastAttempt time.Time
+}
+
+func NewJWKSCache(url string, client *http.Client, refreshInterval time.Duration, minRefreshInterval time.Duration) *JWKSCache {
+	return &JWKSCache{
+		url:                url,
+		client:             client,
+		refreshInterval:    refreshInterval,
+		minRefreshInterval: minRefreshInterval,
+		keys:               map[string]verificationKey{},
+	}
+}
+
+// Run периодически обновляет ключи, пока не отменен ctx
+func (c *JWKSCache) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Refresh(ctx); err != nil {
+				logger.WithError(err).Warn("failed to refresh JWKS")
+			}
+		}
+	}
+}
+
+// Key возвращает ключ по kid. Неизвестный kid приводит к внеплановому обновлению,
+// но не чаще minRefreshInterval, чтобы токены с мусорным kid не нагружали сервис авторизации.
+// Одновременные запросы с неизвестным kid ждут одно обновление, а не запускают каждый свое
+func (c *JWKSCache) Key(ctx context.Context, kid string) (verificationKey, error) {
+	if key, ok := c.cachedKey(kid); ok {
+		return key, nil
+	}
+
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+	// Ключ мог появиться, пока ждали обновления, запущенного другим запросом
+	if key, ok := c.cachedKey(kid); ok {
+		return key, nil
+	}
+
+	c.mu.RLock()
+	throttled := time.Since(c.lastAttempt) < c.minRefreshInterval
+	c.mu.RUnlock()
+	if throttled {
+		return verificationKey{}, ErrJWKSKeyNotFound
+	}
+
+	if err := c.Refresh(ctx); err != nil {
+		logger.WithError(err).Warn("failed to refresh JWKS on unknown kid")
+	}
+	if key, ok := c.cachedKey(kid); ok {
+		return key, nil
+	}
+	return verificationKey{}, ErrJWKSKeyNotFound
+}
+
+// Available - удалось ли загрузить ключи при последней попытке
+func (c *JWKSCache) Available() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.available
+}
+
+// Refresh загружает JWKS документ и заменяет закэшированные ключи.
+// При ошибке старые ключи сохраняются
+func (c *JWKSCache) Refresh(ctx context.Context) error {
+	// Время попытки отмечается до запроса, чтобы на время запроса действовал minRefreshInterval
+	c.mu.Lock()
+	c.lastAttempt = time.Now()
+	c.mu.Unlock()
+
+	keys, err := c.fetch(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.available = err == nil
+	if err != nil {
+		return err
+	}
+	c.keys = keys
+	logger.Debugf("Loaded %d keys from JWKS", len(keys))
+	return nil
+}
+
+func (c *JWKSCache) cachedKey(kid string) (verificationKey, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	key, ok := c.keys[kid]
+	return key, ok
+}
+
+func (c *JWKSCache) fetch(ctx context.Context) (map[string]verificationKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("jwks request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwks request failed: unexpected status %d", resp.StatusCode)
+	}
+
+	var doc jwksDocument
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode jwks: %w", err)
+	}
+
+	keys := make(map[string]verificationKey, len(doc.Keys))
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			logger.WithError(err).Warnf("skipping jwks key %q", k.Kid)
+			continue
+		}
+		keys[k.Kid] = verificationKey{key: key, alg: k.Alg}
+	}
+	return keys, nil
+}
+
+// publicKey - публичный ключ из параметров JWK
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %w", err)
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		curve, err := ellipticCurve(k.Crv)
+		if err != nil {
+			return nil, err
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %w", err)
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		// ECDH проверяет, что точка лежит на кривой
+		if _, err := key.ECDH(); err != nil {
+			return nil, fmt.Errorf("invalid EC key: %w", err)
+		}
+		return key, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %w", err)
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
@@ -0,0 +1,185 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// jwksServer - сервис авторизации, который публикует текущий набор ключей и считает запросы
+type jwksServer struct {
+	*httptest.Server
+	requests atomic.Int32
+	delay    time.Duration
+
+	mu   sync.Mutex
+	keys map[string]*rsa.PublicKey
+}
+
+func newJWKSServer(t *testing.T) *jwksServer {
+	t.Helper()
+	s := &jwksServer{keys: map[string]*rsa.PublicKey{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		time.Sleep(s.delay)
+
+		s.mu.Lock()
+		doc := jwksDocument{}
+		for kid, key := range s.keys {
+			doc.Keys = append(doc.Keys, jwk{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				Alg: "RS256",
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(doc)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// rotate заменяет опубликованные ключи новым ключом kid
+func (s *jwksServer) rotate(t *testing.T, kid string) *rsa.PublicKey {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	s.mu.Lock()
+	s.keys = map[string]*rsa.PublicKey{kid: &private.PublicKey}
+	s.mu.Unlock()
+	return &private.PublicKey
+}
+
+func TestJWKSCacheCachesKeys(t *testing.T) {
+	server := newJWKSServer(t)
+	want := server.rotate(t, "k1")
+	cache := NewJWKSCache(server.URL, server.Client(), time.Hour, 0)
+
+	for i := 0; i < 3; i++ {
+		key, err := cache.Key(context.Background(), "k1")
+		if err != nil {
+			t.Fatalf("Key(k1): %v", err)
+		}
+		if !want.Equal(key.key) {
+			t.Fatalf("Key(k1) returned a different key")
+		}
+		if key.alg != "RS256" {
+			t.Fatalf("alg = %q, want RS256", key.alg)
+		}
+	}
+	if got := server.requests.Load(); got != 1 {
+		t.Fatalf("JWKS requests = %d, want 1", got)
+	}
+	if !cache.Available() {
+		t.Fatal("cache is not available after successful load")
+	}
+}
+
+func TestJWKSCacheRefreshesOnRotation(t *testing.T) {
+	server := newJWKSServer(t)
+	server.rotate(t, "k1")
+	cache := NewJWKSCache(server.URL, server.Client(), time.Hour, 0)
+	if err := cache.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	want := server.rotate(t, "k2")
+	key, err := cache.Key(context.Background(), "k2")
+	if err != nil {
+		t.Fatalf("Key(k2) after rotation: %v", err)
+	}
+	if !want.Equal(key.key) {
+		t.Fatal("Key(k2) returned a different key")
+	}
+	if got := server.requests.Load(); got != 2 {
+		t.Fatalf("JWKS requests = %d, want 2", got)
+	}
+
+	// Ключ, выведенный из ротации, больше не принимается
+	if _, err := cache.Key(context.Background(), "k1"); !errors.Is(err, ErrJWKSKeyNotFound) {
+		t.Fatalf("Key(k1) after rotation: err = %v, want ErrJWKSKeyNotFound", err)
+	}
+}
+
+func TestJWKSCacheThrottlesUnknownKid(t *testing.T) {
+	server := newJWKSServer(t)
+	server.rotate(t, "k1")
+	cache := NewJWKSCache(server.URL, server.Client(), time.Hour, time.Hour)
+	if err := cache.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		if _, err := cache.Key(context.Background(), "unknown"); !errors.Is(err, ErrJWKSKeyNotFound) {
+			t.Fatalf("Key(unknown): err = %v, want ErrJWKSKeyNotFound", err)
+		}
+	}
+	if got := server.requests.Load(); got != 1 {
+		t.Fatalf("JWKS requests = %d, want 1", got)
+	}
+}
+
+func TestJWKSCacheCollapsesConcurrentRefreshes(t *testing.T) {
+	server := newJWKSServer(t)
+	server.rotate(t, "k1")
+	server.delay = 50 * time.Millisecond
+	cache := NewJWKSCache(server.URL, server.Client(), time.Hour, time.Minute)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cache.Key(context.Background(), "k1"); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("Key(k1): %v", err)
+	}
+	if got := server.requests.Load(); got != 1 {
+		t.Fatalf("JWKS requests = %d, want 1", got)
+	}
+}
+
+func TestJWKSCacheKeepsKeysWhenUnavailable(t *testing.T) {
+	server := newJWKSServer(t)
+	server.rotate(t, "k1")
+	cache := NewJWKSCache(server.URL, server.Client(), time.Hour, 0)
+	if err := cache.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	server.Close()
+	if err := cache.Refresh(context.Background()); err == nil {
+		t.Fatal("Refresh succeeded with the JWKS server down")
+	}
+	if cache.Available() {
+		t.Fatal("cache is available after failed refresh")
+	}
+	if _, err := cache.Key(context.Background(), "k1"); err != nil {
+		t.Fatalf("Key(k1) after failed refresh: %v", err)
+	}
+}
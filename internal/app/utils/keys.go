@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// verificationKey - публичный ключ и алгоритм, для которого он опубликован (если указан)
+type verificationKey struct {
+	key crypto.PublicKey
+	alg string
+}
+
+// supportedAlgorithms - асимметричные алгоритмы, которые можно разрешить в конфигурации.
+// none и HMAC не поддерживаются: публичный ключ не должен использоваться как общий секрет
+var supportedAlgorithms = map[string]jwt.SigningMethod{
+	"RS256": jwt.SigningMethodRS256,
+	"RS384": jwt.SigningMethodRS384,
+	"RS512": jwt.SigningMethodRS512,
+	"PS256": jwt.SigningMethodPS256,
+	"PS384": jwt.SigningMethodPS384,
+	"PS512": jwt.SigningMethodPS512,
+	"ES256": jwt.SigningMethodES256,
+	"ES384": jwt.SigningMethodES384,
+	"ES512": jwt.SigningMethodES512,
+	"EdDSA": jwt.SigningMethodEdDSA,
+}
+
+// validateAlgorithms проверяет список разрешенных алгоритмов из конфигурации
+func validateAlgorithms(algorithms []string) ([]string, error) {
+	if len(algorithms) == 0 {
+		return nil, errors.New("no signing algorithms allowed")
+	}
+
+	allowed := make([]string, 0, len(algorithms))
+	for _, alg := range algorithms {
+		alg = strings.TrimSpace(alg)
+		if _, ok := supportedAlgorithms[alg]; !ok {
+			return nil, fmt.Errorf("signing algorithm %q is not supported", alg)
+		}
+		allowed = append(allowed, alg)
+	}
+	return allowed, nil
+}
+
+// keyMatchesMethod - ключ подходит алгоритму подписи токена: тип ключа, кривая и alg из JWKS совпадают
+func keyMatchesMethod(k verificationKey, method jwt.SigningMethod) bool {
+	if k.alg != "" && k.alg != method.Alg() {
+		return false
+	}
+
+	switch m := method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		_, ok := k.key.(*rsa.PublicKey)
+		return ok
+	case *jwt.SigningMethodECDSA:
+		key, ok := k.key.(*ecdsa.PublicKey)
+		return ok && key.Curve.Params().BitSize == m.CurveBits
+	case *jwt.SigningMethodEd25519:
+		_, ok := k.key.(ed25519.PublicKey)
+		return ok
+	default:
+		return false
+	}
+}
+
+// parsePublicKeysPEM читает все публичные ключи (RSA, ECDSA, Ed25519) из PEM
+func parsePublicKeysPEM(data []byte) ([]verificationKey, error) {
+	var keys []verificationKey
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		var key crypto.PublicKey
+		var err error
+		switch block.Type {
+		case "PUBLIC KEY":
+			key, err = x509.ParsePKIXPublicKey(block.Bytes)
+		case "RSA PUBLIC KEY":
+			key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", block.Type, err)
+		}
+
+		switch key.(type) {
+		case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+			keys = append(keys, verificationKey{key: key})
+		default:
+			return nil, fmt.Errorf("unsupported public key type %T", key)
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no public keys found in PEM")
+	}
+	return keys, nil
+}
+
+// ellipticCurve - кривая по имени crv из JWK
+func ellipticCurve(crv string) (elliptic.Curve, error) {
+	switch crv {
+	case "P-256":
+		return elliptic.P256(), nil
+	case "P-384":
+		return elliptic.P384(), nil
+	case "P-521":
+		return elliptic.P521(), nil
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", crv)
+	}
+}
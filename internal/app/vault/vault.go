@@ -0,0 +1,255 @@
+package vault
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	logger "github.com/sirupsen/logrus"
+
+	"service-user/internal/app/errs"
+	"service-user/internal/app/models"
+	"service-user/internal/app/utils"
+	"service-user/pkg/envelope"
+)
+
+const duplicateValue = "23505"
+
+// plaintextKeyID - key_id карт, перенесенных миграцией 000005 из user_bank_cards незашифрованными
+const plaintextKeyID = "plaintext"
+
+// Vault - хранилище номеров карт. Номер карты попадает в него один раз при привязке,
+// дальше сервис оперирует только токеном и открытыми признаками карты
+type Vault interface {
+	// TokenizeTx сохраняет номер карты владельца в транзакции привязки карты и возвращает токен с отпечатком карты
+	TokenizeTx(ctx context.Context, tx pgx.Tx, ownerID uuid.UUID, number string, expirationDate string) (models.CardToken, error)
+	// Detokenize возвращает номер карты по токену, нужен только при передаче карты в оплату
+	Detokenize(ctx context.Context, token uuid.UUID) (string, error)
+	// DeleteTx удаляет номер карты из хранилища в транзакции удаления карты
+	DeleteTx(ctx context.Context, tx pgx.Tx, token uuid.UUID) error
+}
+
+// PostgresVault - хранилище номеров карт в таблице card_vault, номера зашифрованы конвертным шифрованием
+type PostgresVault struct {
+	db             *pgxpool.Pool
+	encrypter      *envelope.Encrypter
+	fingerprintKey []byte
+}
+
+// NewPostgresVault - конструктор хранилища, fingerprintKey - ключ HMAC для отпечатков карт
+func NewPostgresVault(db *pgxpool.Pool, encrypter *envelope.Encrypter, fingerprintKey []byte) *PostgresVault {
+	return &PostgresVault{
+		db:             db,
+		encrypter:      encrypter,
+		fingerprintKey: fingerprintKey,
+	}
+}
+
+func (v *PostgresVault) TokenizeTx(ctx context.Context, tx pgx.Tx, ownerID uuid.UUID, number string, expirationDate string) (models.CardToken, error) {
+	// Токен нужен до шифрования: шифротекст привязывается к нему
+	token := uuid.New()
+	sealed, err := v.encrypter.Encrypt([]byte(number), cardAAD(ownerID, token))
+	if err != nil {
+		logger.Errorf("Error while encrypting card number %v", err)
+		return models.CardToken{}, errs.ErrTokenizeCard
+	}
+
+	card := models.CardToken{
+		Token: token,
+		Fingerprint: models.CardFingerprint{
+			Last4:          utils.CardLast4(number),
+			Brand:          utils.CardBrand(number),
+			ExpirationDate: expirationDate,
+		},
+	}
+
+	query := `
+		INSERT INTO card_vault (token, owner_id, fingerprint, card_number_encrypted, data_key, key_id, aad_bound, card_last4, brand)
+		VALUES ($1, $2, $3, $4, $5, $6, TRUE, $7, $8)`
+	_, err = tx.Exec(ctx, query, token, ownerID, v.fingerprint(number), sealed.Ciphertext, sealed.DataKey, sealed.KeyID,
+		card.Fingerprint.Last4, card.Fingerprint.Brand)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == duplicateValue {
+			return models.CardToken{}, errs.ErrCardAlreadyExists
+		}
+		logger.Errorf("Error while saving card to vault %v", err)
+		return models.CardToken{}, errs.ErrTokenizeCard
+	}
+	return card, nil
+}
+
+func (v *PostgresVault) Detokenize(ctx context.Context, token uuid.UUID) (string, error) {
+	var sealed envelope.Sealed
+	var ownerID uuid.UUID
+	var aadBound bool
+	query := `SELECT owner_id, card_number_encrypted, data_key, key_id, aad_bound FROM card_vault WHERE token = $1`
+	err := v.db.QueryRow(ctx, query, token).Scan(&ownerID, &sealed.Ciphertext, &sealed.DataKey, &sealed.KeyID, &aadBound)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", errs.ErrCardTokenNotFound
+		}
+		logger.Errorf("Error while reading card from vault %v", err)
+		return "", errs.ErrDetokenizeCard
+	}
+
+	number, err := v.open(ownerID, token, aadBound, sealed)
+	if err != nil {
+		logger.Errorf("Error while decrypting card %v: %v", token, err)
+		return "", errs.ErrDetokenizeCard
+	}
+	return number, nil
+}
+
+func (v *PostgresVault) DeleteTx(ctx context.Context, tx pgx.Tx, token uuid.UUID) error {
+	_, err := tx.Exec(ctx, `DELETE FROM card_vault WHERE token = $1`, token)
+	if err != nil {
+		logger.Errorf("Error while deleting card from vault %v", err)
+		return errs.ErrDeleteCard
+	}
+	return nil
+}
+
+// Reencrypt перешифровывает номера карт новым ключом данных под активным мастер-ключом с привязкой
+// к владельцу и токену и пересчитывает отпечатки карт текущим ключом HMAC. Ключ данных меняется
+// целиком, а не только перешифровывается: старый мастер-ключ мог быть скомпрометирован.
+// Обрабатывает одну пачку и возвращает число перешифрованных строк. Строки, заблокированные другими
+// транзакциями, пропускаются, их берет следующий вызов, поэтому вызывать до PendingReencryption() == 0
+func (v *PostgresVault) Reencrypt(ctx context.Context, batchSize int) (int, error) {
+	tx, err := v.db.Begin(ctx)
+	if err != nil {
+		logger.Errorf("Error while starting re-encryption %v", err)
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		SELECT token, owner_id, aad_bound, card_number_encrypted, data_key, key_id
+		FROM card_vault
+		WHERE key_id <> $1 OR NOT aad_bound
+		ORDER BY token
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED`
+	rows, err := tx.Query(ctx, query, v.encrypter.ActiveKeyID(), batchSize)
+	if err != nil {
+		logger.Errorf("Error while selecting cards for re-encryption %v", err)
+		return 0, err
+	}
+
+	type staleCard struct {
+		token    uuid.UUID
+		ownerID  uuid.UUID
+		aadBound bool
+		sealed   envelope.Sealed
+	}
+	var cards []staleCard
+	for rows.Next() {
+		var card staleCard
+		err = rows.Scan(&card.token, &card.ownerID, &card.aadBound, &card.sealed.Ciphertext, &card.sealed.DataKey, &card.sealed.KeyID)
+		if err != nil {
+			rows.Close()
+			logger.Errorf("Error while scanning card for re-encryption %v", err)
+			return 0, err
+		}
+		cards = append(cards, card)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		logger.Errorf("Error while reading cards for re-encryption %v", err)
+		return 0, err
+	}
+
+	for _, card := range cards {
+		number, err := v.open(card.ownerID, card.token, card.aadBound, card.sealed)
+		if err != nil {
+			logger.Errorf("Error while decrypting card %v: %v", card.token, err)
+			return 0, err
+		}
+		sealed, err := v.encrypter.Encrypt([]byte(number), cardAAD(card.ownerID, card.token))
+		if err != nil {
+			logger.Errorf("Error while re-encrypting card %v: %v", card.token, err)
+			return 0, err
+		}
+
+		// Дубликаты, добавленные до появления хранилища, остаются без отпечатка
+		query = `
+			UPDATE card_vault v
+			SET card_number_encrypted = $1,
+			    data_key = $2,
+			    key_id = $3,
+			    aad_bound = TRUE,
+			    brand = $4,
+			    fingerprint = CASE
+			        WHEN EXISTS (
+			            SELECT 1 FROM card_vault d
+			            WHERE d.owner_id = v.owner_id AND d.fingerprint = $5 AND d.token <> v.token
+			        ) THEN NULL
+			        ELSE $5
+			    END
+			WHERE v.token = $6`
+		_, err = tx.Exec(ctx, query, sealed.Ciphertext, sealed.DataKey, sealed.KeyID, utils.CardBrand(number),
+			v.fingerprint(number), card.token)
+		if err != nil {
+			logger.Errorf("Error while saving re-encrypted card %v: %v", card.token, err)
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		logger.Errorf("Error while committing re-encryption %v", err)
+		return 0, err
+	}
+	return len(cards), nil
+}
+
+// PendingReencryption - число карт, которые еще нужно перешифровать, включая заблокированные сейчас
+func (v *PostgresVault) PendingReencryption(ctx context.Context) (int, error) {
+	var pending int
+	query := `SELECT COUNT(*) FROM card_vault WHERE key_id <> $1 OR NOT aad_bound`
+	err := v.db.QueryRow(ctx, query, v.encrypter.ActiveKeyID()).Scan(&pending)
+	if err != nil {
+		logger.Errorf("Error while counting cards for re-encryption %v", err)
+		return 0, err
+	}
+	return pending, nil
+}
+
+// open - номер карты из строки хранилища. Номера, перенесенные миграцией открытыми,
+// лежат в card_number_encrypted как есть до перешифрования
+func (v *PostgresVault) open(ownerID uuid.UUID, token uuid.UUID, aadBound bool, sealed envelope.Sealed) (string, error) {
+	if sealed.KeyID == plaintextKeyID && !aadBound {
+		return string(sealed.Ciphertext), nil
+	}
+	number, err := v.encrypter.Decrypt(sealed, storedAAD(ownerID, token, aadBound))
+	if err != nil {
+		return "", err
+	}
+	return string(number), nil
+}
+
+// cardAAD - данные, к которым привязан шифротекст номера карты: строку хранилища нельзя подменить
+// шифротекстом другой карты или другого владельца
+func cardAAD(ownerID uuid.UUID, token uuid.UUID) []byte {
+	return []byte("card_vault:" + ownerID.String() + ":" + token.String())
+}
+
+// storedAAD - aad строки хранилища, строки до перешифрования зашифрованы без привязки
+func storedAAD(ownerID uuid.UUID, token uuid.UUID, aadBound bool) []byte {
+	if !aadBound {
+		return nil
+	}
+	return cardAAD(ownerID, token)
+}
+
+// fingerprint - HMAC номера карты, одинаковый для одной и той же карты
+func (v *PostgresVault) fingerprint(number string) string {
+	mac := hmac.New(sha256.New, v.fingerprintKey)
+	mac.Write([]byte(number))
+	return hex.EncodeToString(mac.Sum(nil))
+}
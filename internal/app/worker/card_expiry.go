@@ -0,0 +1,56 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+
+	"service-user/internal/app/repository"
+)
+
+const cardExpiryBatchSize = 100
+
+// CardExpiryWorker - периодически ищет карты, срок действия которых скоро истекает,
+// и записывает по каждой событие card.expiring в outbox. Экземпляры сервиса забирают
+// разные карты, поэтому уведомление о карте отправляется один раз
+type CardExpiryWorker struct {
+	repo     repository.CardRepository
+	within   time.Duration
+	interval time.Duration
+}
+
+func NewCardExpiryWorker(repo repository.CardRepository, notifyDays int, interval time.Duration) *CardExpiryWorker {
+	return &CardExpiryWorker{
+		repo:     repo,
+		within:   time.Duration(notifyDays) * 24 * time.Hour,
+		interval: interval,
+	}
+}
+
+// Run выполняет проверку сразу и затем с заданным интервалом, пока не отменен ctx
+func (w *CardExpiryWorker) Run(ctx context.Context) {
+	logger.Infof("Card expiry worker started, interval %v", w.interval)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.check(ctx)
+
+		select {
+		case <-ctx.Done():
+			logger.Info("Card expiry worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *CardExpiryWorker) check(ctx context.Context) {
+	for ctx.Err() == nil {
+		notified, err := w.repo.NotifyExpiringCards(ctx, w.within, cardExpiryBatchSize)
+		if err != nil || notified < cardExpiryBatchSize {
+			return
+		}
+	}
+}
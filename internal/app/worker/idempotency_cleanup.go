@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+
+	"service-user/internal/app/service"
+)
+
+// IdempotencyCleanupWorker - периодически удаляет истекшие ключи идемпотентности
+type IdempotencyCleanupWorker struct {
+	service  service.IdempotencyService
+	interval time.Duration
+}
+
+func NewIdempotencyCleanupWorker(service service.IdempotencyService, interval time.Duration) *IdempotencyCleanupWorker {
+	return &IdempotencyCleanupWorker{
+		service:  service,
+		interval: interval,
+	}
+}
+
+// Run удаляет ключи сразу и затем с заданным интервалом, пока не отменен ctx
+func (w *IdempotencyCleanupWorker) Run(ctx context.Context) {
+	logger.Infof("Idempotency cleanup worker started, interval %v", w.interval)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if deleted, err := w.service.DeleteExpiredIdempotencyKeys(ctx); err == nil && deleted > 0 {
+			logger.Infof("Deleted %d expired idempotency keys", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Info("Idempotency cleanup worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
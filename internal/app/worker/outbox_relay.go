@@ -0,0 +1,94 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+
+	"service-user/internal/app/events"
+	"service-user/internal/app/models"
+	"service-user/internal/app/repository"
+	"service-user/internal/configs"
+)
+
+// OutboxRelayWorker - публикует события из outbox. Событие удаляется только после успешной публикации,
+// поэтому при сбое оно может уйти повторно: подписчики отбрасывают дубликаты по id события
+type OutboxRelayWorker struct {
+	repo      repository.OutboxRepository
+	publisher events.Publisher
+	cfg       *configs.EventsConfig
+}
+
+func NewOutboxRelayWorker(repo repository.OutboxRepository, publisher events.Publisher, cfg *configs.EventsConfig) *OutboxRelayWorker {
+	return &OutboxRelayWorker{
+		repo:      repo,
+		publisher: publisher,
+		cfg:       cfg,
+	}
+}
+
+// Run публикует события сразу и затем с заданным интервалом, пока не отменен ctx
+func (w *OutboxRelayWorker) Run(ctx context.Context) {
+	logger.Infof("Outbox relay worker started, interval %v", w.cfg.RelayInterval)
+	ticker := time.NewTicker(w.cfg.RelayInterval)
+	defer ticker.Stop()
+
+	for {
+		w.relay(ctx)
+
+		select {
+		case <-ctx.Done():
+			logger.Info("Outbox relay worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *OutboxRelayWorker) relay(ctx context.Context) {
+	for ctx.Err() == nil {
+		claimed, err := w.repo.ClaimOutboxEvents(ctx, w.cfg.RelayBatchSize, w.cfg.RelayLease)
+		if err != nil {
+			return
+		}
+
+		for _, outboxEvent := range claimed {
+			w.publish(ctx, outboxEvent)
+		}
+
+		if len(claimed) < w.cfg.RelayBatchSize {
+			return
+		}
+	}
+}
+
+func (w *OutboxRelayWorker) publish(ctx context.Context, outboxEvent models.OutboxEvent) {
+	event := events.Event{
+		ID:         outboxEvent.ID,
+		Type:       outboxEvent.Type,
+		OccurredAt: outboxEvent.OccurredAt,
+		Payload:    outboxEvent.Payload,
+		Key:        outboxEvent.Key,
+	}
+	if err := w.publisher.Publish(ctx, event); err != nil {
+		delay := w.backoff(outboxEvent.Attempts)
+		logger.Errorf("Error while publishing %s event %v, attempt %d, retry in %v: %v",
+			event.Type, event.ID, outboxEvent.Attempts+1, delay, err)
+		_ = w.repo.RetryOutboxEvent(ctx, event.ID, delay, err.Error())
+		return
+	}
+	_ = w.repo.MarkOutboxEventPublished(ctx, event.ID)
+}
+
+// backoff - задержка перед следующей попыткой: retry_base, удваивается после каждой неудачи до retry_max
+func (w *OutboxRelayWorker) backoff(attempts int) time.Duration {
+	delay := w.cfg.RetryBase
+	for i := 0; i < attempts && delay < w.cfg.RetryMax; i++ {
+		delay *= 2
+	}
+	if delay > w.cfg.RetryMax {
+		delay = w.cfg.RetryMax
+	}
+	return delay
+}
@@ -0,0 +1,61 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+
+	"service-user/internal/app/repository"
+)
+
+const profilePurgeBatchSize = 100
+
+// ProfilePurgeWorker - периодически окончательно удаляет профили, срок восстановления которых истек.
+// Корзина и карты профиля удаляются каскадно
+type ProfilePurgeWorker struct {
+	repo     repository.ProfileRepository
+	grace    time.Duration
+	interval time.Duration
+}
+
+func NewProfilePurgeWorker(repo repository.ProfileRepository, grace time.Duration, interval time.Duration) *ProfilePurgeWorker {
+	return &ProfilePurgeWorker{
+		repo:     repo,
+		grace:    grace,
+		interval: interval,
+	}
+}
+
+// Run выполняет удаление сразу и затем с заданным интервалом, пока не отменен ctx
+func (w *ProfilePurgeWorker) Run(ctx context.Context) {
+	logger.Infof("Profile purge worker started, grace period %v, interval %v", w.grace, w.interval)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			logger.Info("Profile purge worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *ProfilePurgeWorker) purge(ctx context.Context) {
+	for ctx.Err() == nil {
+		purged, err := w.repo.PurgeDeletedProfiles(ctx, w.grace, profilePurgeBatchSize)
+		if err != nil {
+			return
+		}
+		if purged > 0 {
+			logger.Infof("Purged %d deleted user-profiles", purged)
+		}
+		if purged < profilePurgeBatchSize {
+			return
+		}
+	}
+}
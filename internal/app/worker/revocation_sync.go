@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+
+	"service-user/internal/app/service"
+)
+
+// RevocationSyncWorker - периодически обновляет список отзыва токенов в памяти,
+// чтобы отзыв, сделанный на другом экземпляре сервиса, начал действовать и здесь
+type RevocationSyncWorker struct {
+	service  service.RevocationService
+	interval time.Duration
+}
+
+func NewRevocationSyncWorker(service service.RevocationService, interval time.Duration) *RevocationSyncWorker {
+	return &RevocationSyncWorker{
+		service:  service,
+		interval: interval,
+	}
+}
+
+// Run обновляет список сразу и затем с заданным интервалом, пока не отменен ctx
+func (w *RevocationSyncWorker) Run(ctx context.Context) {
+	logger.Infof("Revocation sync worker started, interval %v", w.interval)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.service.RefreshRevocations(ctx); err != nil {
+			logger.Warnf("Failed to refresh revoked tokens, using previous list: %v", err)
+		}
+		if err := w.service.DeleteExpiredRevocations(ctx); err != nil {
+			logger.Warnf("Failed to delete expired revocations: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Info("Revocation sync worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
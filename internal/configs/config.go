@@ -17,6 +17,9 @@ type ServerConfig struct {
 	ReadTimeout    time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout   time.Duration `mapstructure:"write_timeout"`
 	MaxHeaderBytes int           `mapstructure:"max_header_bytes"`
+	// Прокси (адреса или подсети), которым доверяется X-Forwarded-For. Пустой список - адрес клиента
+	// всегда берется из соединения
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
 // Конфигурация логирования
@@ -32,17 +35,120 @@ type PostgresConfig struct {
 	MigratePath string `mapstructure:"migrate_path"`
 }
 
+// Загрузка ключей сервиса авторизации из JWKS
+type JWKSConfig struct {
+	Enabled            bool          `mapstructure:"enabled"`
+	Url                string        `mapstructure:"url"`                  // по умолчанию auth.url + /.well-known/jwks.json
+	RefreshInterval    time.Duration `mapstructure:"refresh_interval"`     // плановое обновление ключей
+	MinRefreshInterval time.Duration `mapstructure:"min_refresh_interval"` // минимальный интервал обновления при неизвестном kid
+	Timeout            time.Duration `mapstructure:"timeout"`
+}
+
 type AuthConfig struct {
-	Url       string `mapstructure:"url"`
-	PublicKey string `mapstructure:"public_key"`
+	Url            string        `mapstructure:"url"`
+	PublicKey      string        `mapstructure:"public_key"`      // локальные ключи (PEM, можно несколько), используются если JWKS недоступен
+	Algorithms     []string      `mapstructure:"algorithms"`      // разрешенные алгоритмы подписи токена
+	Issuer         string        `mapstructure:"issuer"`          // ожидаемый iss, пустое значение отключает проверку
+	Audience       string        `mapstructure:"audience"`        // ожидаемый aud, пустое значение отключает проверку
+	Leeway         time.Duration `mapstructure:"leeway"`          // допустимое расхождение часов для exp, nbf и iat
+	RequiredClaims []string      `mapstructure:"required_claims"` // claims, без которых токен отклоняется
+	EnforceScopes  bool          `mapstructure:"enforce_scopes"`  // отклонять запросы без нужного scope, иначе только писать в лог
+	RevocationSync time.Duration `mapstructure:"revocation_sync"` // как часто обновлять список отзыва токенов из базы
+	// максимальное время жизни access token, отзыв всех токенов пользователя хранится столько же
+	MaxTokenLifetime time.Duration `mapstructure:"max_token_lifetime"`
+	Jwks             JWKSConfig    `mapstructure:"jwks"`
+	TokenSources     []string      `mapstructure:"token_sources"` // где и в каком порядке искать токен: cookie, bearer, header
+	CookieName       string        `mapstructure:"cookie_name"`
+	HeaderName       string        `mapstructure:"header_name"`
+}
+
+// Конфигурация шифрования номеров карт
+type EncryptionConfig struct {
+	ActiveKeyID string            `mapstructure:"active_key_id"`
+	MasterKeys  map[string]string `mapstructure:"master_keys"` // id ключа -> env:ПЕРЕМЕННАЯ или путь к файлу мастер-ключа
+	// Ключ HMAC для отпечатков карт (env:ПЕРЕМЕННАЯ или путь к файлу). Отпечатки старых карт пересчитывает
+	// перешифрование, до его окончания такие карты не находятся как дубликаты
+	FingerprintKey string `mapstructure:"fingerprint_key"`
+}
+
+// Сервис каталога товаров, из него берутся цены товаров в корзине
+type CatalogConfig struct {
+	Url     string        `mapstructure:"url"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// Конфигурация работы с банковскими картами
+type CardsConfig struct {
+	ExpiryNotifyDays    int           `mapstructure:"expiry_notify_days"`    // за сколько дней до истечения срока уведомлять
+	ExpiryCheckInterval time.Duration `mapstructure:"expiry_check_interval"` // как часто искать истекающие карты
+}
+
+// Конфигурация профилей пользователей
+type ProfilesConfig struct {
+	DeleteGracePeriod time.Duration `mapstructure:"delete_grace_period"` // сколько удаленный профиль можно восстановить
+	PurgeInterval     time.Duration `mapstructure:"purge_interval"`      // как часто окончательно удалять профили после срока восстановления
+	RequireIfMatch    bool          `mapstructure:"require_if_match"`    // PATCH и DELETE профиля без If-Match отклоняются с 428
+	// Ключ HMAC отметок о стертых пользователях (env:ПЕРЕМЕННАЯ или путь к файлу)
+	ErasureTombstoneKey string `mapstructure:"erasure_tombstone_key"`
+}
+
+// Топик Kafka для набора типов событий
+type TopicRoute struct {
+	Topic  string   `mapstructure:"topic"`
+	Events []string `mapstructure:"events"`
+}
+
+// Конфигурация публикации событий в Kafka
+type KafkaConfig struct {
+	Brokers      []string      `mapstructure:"brokers"`
+	ClientID     string        `mapstructure:"client_id"`
+	Topics       []TopicRoute  `mapstructure:"topics"`
+	DefaultTopic string        `mapstructure:"default_topic"` // топик для типов событий без маршрута, пустое значение - ошибка публикации
+	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+}
+
+// Конфигурация публикации доменных событий
+type EventsConfig struct {
+	Publisher      string        `mapstructure:"publisher"`        // log, stdout, file или kafka
+	File           string        `mapstructure:"file"`             // файл для публикации file
+	RelayInterval  time.Duration `mapstructure:"relay_interval"`   // как часто проверять outbox
+	RelayBatchSize int           `mapstructure:"relay_batch_size"` // сколько событий публиковать за раз
+	RelayLease     time.Duration `mapstructure:"relay_lease"`      // на сколько экземпляр забирает события себе
+	RetryBase      time.Duration `mapstructure:"retry_base"`       // задержка после первой неудачной публикации, дальше удваивается
+	RetryMax       time.Duration `mapstructure:"retry_max"`        // максимальная задержка между попытками
+	Kafka          KafkaConfig   `mapstructure:"kafka"`
+}
+
+// Конфигурация обработки событий сервиса авторизации, брокеры берутся из events.kafka
+type UserEventsConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	Topic           string        `mapstructure:"topic"`
+	GroupID         string        `mapstructure:"group_id"`
+	DeadLetterTopic string        `mapstructure:"dead_letter_topic"` // куда отправляются события, не прошедшие проверку
+	RetryBase       time.Duration `mapstructure:"retry_base"`        // задержка повтора после первой ошибки обработки, дальше удваивается
+	RetryMax        time.Duration `mapstructure:"retry_max"`         // максимальная задержка между повторами
+}
+
+// Конфигурация повторов запросов с Idempotency-Key
+type IdempotencyConfig struct {
+	TTL             time.Duration `mapstructure:"ttl"`              // сколько хранится ответ, после этого ключ можно использовать заново
+	Lease           time.Duration `mapstructure:"lease"`            // сколько ключ занят выполняющимся запросом, если экземпляр упал
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"` // как часто удалять истекшие ключи
 }
 
 // Полная конфигурация
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Logging  LoggerConfig   `mapstructure:"logging"`
-	Database PostgresConfig `mapstructure:"database"`
-	Auth     AuthConfig     `mapstructure:"auth"`
+	Server      ServerConfig      `mapstructure:"server"`
+	Logging     LoggerConfig      `mapstructure:"logging"`
+	Database    PostgresConfig    `mapstructure:"database"`
+	Auth        AuthConfig        `mapstructure:"auth"`
+	Encryption  EncryptionConfig  `mapstructure:"encryption"`
+	Catalog     CatalogConfig     `mapstructure:"catalog"`
+	Cards       CardsConfig       `mapstructure:"cards"`
+	Profiles    ProfilesConfig    `mapstructure:"profiles"`
+	Events      EventsConfig      `mapstructure:"events"`
+	UserEvents  UserEventsConfig  `mapstructure:"user_events"`
+	Idempotency IdempotencyConfig `mapstructure:"idempotency"`
 }
 
 // LoadConfig загружает конфигурацию из файлов и переменных окружения
@@ -80,6 +186,108 @@ func LoadConfig(path string) (*Config, error) {
 	if config.Server.WriteTimeout <= 0 {
 		config.Server.WriteTimeout = 10 * time.Second
 	}
+	if len(config.Auth.Algorithms) == 0 {
+		config.Auth.Algorithms = []string{"RS256"}
+	}
+	if len(config.Auth.RequiredClaims) == 0 {
+		config.Auth.RequiredClaims = []string{"exp", "sub"}
+	}
+	if config.Auth.RevocationSync <= 0 {
+		config.Auth.RevocationSync = 30 * time.Second
+	}
+	if config.Auth.MaxTokenLifetime <= 0 {
+		config.Auth.MaxTokenLifetime = 24 * time.Hour
+	}
+	if config.Auth.Jwks.Url == "" {
+		config.Auth.Jwks.Url = strings.TrimSuffix(config.Auth.Url, "/") + "/.well-known/jwks.json"
+	}
+	if config.Auth.Jwks.RefreshInterval <= 0 {
+		config.Auth.Jwks.RefreshInterval = 10 * time.Minute
+	}
+	if config.Auth.Jwks.MinRefreshInterval <= 0 {
+		config.Auth.Jwks.MinRefreshInterval = 30 * time.Second
+	}
+	if config.Auth.Jwks.Timeout <= 0 {
+		config.Auth.Jwks.Timeout = 5 * time.Second
+	}
+	if len(config.Auth.TokenSources) == 0 {
+		config.Auth.TokenSources = []string{"cookie", "bearer"}
+	}
+	if config.Auth.CookieName == "" {
+		config.Auth.CookieName = "access_token"
+	}
+	if config.Auth.HeaderName == "" {
+		config.Auth.HeaderName = "X-Access-Token"
+	}
+	if config.Catalog.Timeout <= 0 {
+		config.Catalog.Timeout = 3 * time.Second
+	}
+	if config.Cards.ExpiryNotifyDays <= 0 {
+		config.Cards.ExpiryNotifyDays = 30
+	}
+	if config.Cards.ExpiryCheckInterval <= 0 {
+		config.Cards.ExpiryCheckInterval = time.Hour
+	}
+	if config.Profiles.DeleteGracePeriod <= 0 {
+		config.Profiles.DeleteGracePeriod = 30 * 24 * time.Hour
+	}
+	if config.Profiles.PurgeInterval <= 0 {
+		config.Profiles.PurgeInterval = time.Hour
+	}
+	if config.Events.Publisher == "" {
+		config.Events.Publisher = "log"
+	}
+	if config.Events.File == "" {
+		config.Events.File = "events.jsonl"
+	}
+	if config.Events.RelayInterval <= 0 {
+		config.Events.RelayInterval = time.Second
+	}
+	if config.Events.RelayBatchSize <= 0 {
+		config.Events.RelayBatchSize = 100
+	}
+	if config.Events.RelayLease <= 0 {
+		config.Events.RelayLease = 30 * time.Second
+	}
+	if config.Events.RetryBase <= 0 {
+		config.Events.RetryBase = time.Second
+	}
+	if config.Events.RetryMax <= 0 {
+		config.Events.RetryMax = 5 * time.Minute
+	}
+	if config.Events.Kafka.ClientID == "" {
+		config.Events.Kafka.ClientID = "service-user"
+	}
+	if config.Events.Kafka.WriteTimeout <= 0 {
+		config.Events.Kafka.WriteTimeout = 10 * time.Second
+	}
+	if config.UserEvents.Topic == "" {
+		config.UserEvents.Topic = "auth.user.events.v1"
+	}
+	if config.UserEvents.GroupID == "" {
+		config.UserEvents.GroupID = "service-user"
+	}
+	if config.UserEvents.DeadLetterTopic == "" {
+		config.UserEvents.DeadLetterTopic = "service-user.auth-user-events.dlq"
+	}
+	if config.UserEvents.RetryBase <= 0 {
+		config.UserEvents.RetryBase = time.Second
+	}
+	if config.UserEvents.RetryMax <= 0 {
+		config.UserEvents.RetryMax = time.Minute
+	}
+	if config.Idempotency.TTL <= 0 {
+		config.Idempotency.TTL = 24 * time.Hour
+	}
+	if config.Idempotency.Lease <= 0 {
+		config.Idempotency.Lease = time.Minute
+	}
+	if config.Idempotency.CleanupInterval <= 0 {
+		config.Idempotency.CleanupInterval = time.Hour
+	}
+	if config.UserEvents.Enabled && len(config.Events.Kafka.Brokers) == 0 {
+		return nil, fmt.Errorf("user events consumer requires events.kafka.brokers")
+	}
 
 	return &config, nil
 }
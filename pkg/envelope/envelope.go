@@ -0,0 +1,122 @@
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+const dataKeySize = 32
+
+var ErrMalformedCiphertext = errors.New("malformed ciphertext")
+
+// Sealed - зашифрованные данные вместе с зашифрованным ключом данных
+type Sealed struct {
+	KeyID      string // id мастер-ключа, которым зашифрован DataKey
+	DataKey    []byte // ключ данных, зашифрованный мастер-ключом
+	Ciphertext []byte // данные, зашифрованные ключом данных
+}
+
+// Encrypter - конвертное шифрование: данные шифруются одноразовым AES-GCM ключом,
+// а он сам - мастер-ключом из KeyProvider
+type Encrypter struct {
+	keys KeyProvider
+}
+
+func NewEncrypter(keys KeyProvider) *Encrypter {
+	return &Encrypter{keys: keys}
+}
+
+// ActiveKeyID - id мастер-ключа, которым шифруются новые данные
+func (e *Encrypter) ActiveKeyID() string {
+	return e.keys.ActiveKeyID()
+}
+
+// Encrypt шифрует данные новым ключом данных под активным мастер-ключом. aad - дополнительные данные,
+// к которым привязан шифротекст (например, id записи): расшифровать его можно только с теми же aad
+func (e *Encrypter) Encrypt(plaintext []byte, aad []byte) (Sealed, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return Sealed{}, fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	ciphertext, err := seal(dataKey, plaintext, aad)
+	if err != nil {
+		return Sealed{}, err
+	}
+
+	keyID := e.keys.ActiveKeyID()
+	wrapped, err := e.wrap(keyID, dataKey)
+	if err != nil {
+		return Sealed{}, err
+	}
+
+	return Sealed{KeyID: keyID, DataKey: wrapped, Ciphertext: ciphertext}, nil
+}
+
+// Decrypt расшифровывает данные любым известным мастер-ключом, aad должны совпадать с переданными в Encrypt
+func (e *Encrypter) Decrypt(sealed Sealed, aad []byte) ([]byte, error) {
+	dataKey, err := e.unwrap(sealed.KeyID, sealed.DataKey)
+	if err != nil {
+		return nil, err
+	}
+	return open(dataKey, sealed.Ciphertext, aad)
+}
+
+func (e *Encrypter) wrap(keyID string, dataKey []byte) ([]byte, error) {
+	masterKey, err := e.keys.MasterKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return seal(masterKey, dataKey, nil)
+}
+
+func (e *Encrypter) unwrap(keyID string, wrapped []byte) ([]byte, error) {
+	masterKey, err := e.keys.MasterKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return open(masterKey, wrapped, nil)
+}
+
+// seal - AES-GCM, nonce записывается перед шифротекстом
+func seal(key, plaintext, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func open(key, data, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, ErrMalformedCiphertext
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedCiphertext, err)
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
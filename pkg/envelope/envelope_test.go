@@ -0,0 +1,162 @@
+package envelope
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+// testKeys - мастер-ключи из переменных окружения, по одному случайному ключу на id
+func testKeys(t *testing.T, activeKeyID string, keyIDs ...string) *StaticKeyProvider {
+	t.Helper()
+	sources := make(map[string]string, len(keyIDs))
+	for _, keyID := range keyIDs {
+		name := "ENVELOPE_TEST_KEY_" + keyID
+		t.Setenv(name, base64.StdEncoding.EncodeToString(randomKey(t)))
+		sources[keyID] = envKeyPrefix + name
+	}
+	keys, err := NewStaticKeyProvider(activeKeyID, sources)
+	if err != nil {
+		t.Fatalf("NewStaticKeyProvider: %v", err)
+	}
+	return keys
+}
+
+func randomKey(t *testing.T) []byte {
+	t.Helper()
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return key
+}
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	encrypter := NewEncrypter(testKeys(t, "v1", "v1"))
+	plaintext := []byte("4111111111111111")
+	aad := []byte("card_vault:owner:token")
+
+	sealed, err := encrypter.Encrypt(plaintext, aad)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if sealed.KeyID != "v1" {
+		t.Fatalf("KeyID = %q, want v1", sealed.KeyID)
+	}
+	if bytes.Contains(sealed.Ciphertext, plaintext) {
+		t.Fatal("ciphertext contains the plaintext")
+	}
+
+	got, err := encrypter.Decrypt(sealed, aad)
+	if err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Fatalf("Decrypt = %q, want %q", got, plaintext)
+	}
+
+	// Каждое шифрование со своим ключом данных и nonce
+	again, err := encrypter.Encrypt(plaintext, aad)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if bytes.Equal(again.Ciphertext, sealed.Ciphertext) || bytes.Equal(again.DataKey, sealed.DataKey) {
+		t.Fatal("two encryptions of the same plaintext are equal")
+	}
+}
+
+func TestDecryptRequiresSameAAD(t *testing.T) {
+	encrypter := NewEncrypter(testKeys(t, "v1", "v1"))
+	sealed, err := encrypter.Encrypt([]byte("4111111111111111"), []byte("card_vault:owner-a:token"))
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	// Шифротекст, перенесенный в чужую строку, не расшифровывается
+	for _, aad := range [][]byte{[]byte("card_vault:owner-b:token"), nil} {
+		if _, err = encrypter.Decrypt(sealed, aad); !errors.Is(err, ErrMalformedCiphertext) {
+			t.Fatalf("Decrypt with aad %q: err = %v, want ErrMalformedCiphertext", aad, err)
+		}
+	}
+}
+
+func TestDecryptRejectsTamperedData(t *testing.T) {
+	encrypter := NewEncrypter(testKeys(t, "v1", "v1"))
+	sealed, err := encrypter.Encrypt([]byte("4111111111111111"), nil)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	tampered := sealed
+	tampered.Ciphertext = append([]byte(nil), sealed.Ciphertext...)
+	tampered.Ciphertext[len(tampered.Ciphertext)-1] ^= 1
+	if _, err = encrypter.Decrypt(tampered, nil); !errors.Is(err, ErrMalformedCiphertext) {
+		t.Fatalf("Decrypt tampered ciphertext: err = %v, want ErrMalformedCiphertext", err)
+	}
+
+	short := sealed
+	short.Ciphertext = sealed.Ciphertext[:4]
+	if _, err = encrypter.Decrypt(short, nil); !errors.Is(err, ErrMalformedCiphertext) {
+		t.Fatalf("Decrypt short ciphertext: err = %v, want ErrMalformedCiphertext", err)
+	}
+
+	unknown := sealed
+	unknown.KeyID = "v9"
+	if _, err = encrypter.Decrypt(unknown, nil); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("Decrypt with unknown key: err = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestMasterKeyRotation(t *testing.T) {
+	t.Setenv("ENVELOPE_TEST_KEY_v1", base64.StdEncoding.EncodeToString(randomKey(t)))
+	t.Setenv("ENVELOPE_TEST_KEY_v2", base64.StdEncoding.EncodeToString(randomKey(t)))
+	sources := map[string]string{"v1": "env:ENVELOPE_TEST_KEY_v1"}
+	oldKeys, err := NewStaticKeyProvider("v1", sources)
+	if err != nil {
+		t.Fatalf("NewStaticKeyProvider: %v", err)
+	}
+	aad := []byte("card_vault:owner:token")
+	sealed, err := NewEncrypter(oldKeys).Encrypt([]byte("4111111111111111"), aad)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	// Новый активный ключ v2, старый v1 еще известен
+	sources["v2"] = "env:ENVELOPE_TEST_KEY_v2"
+	keys, err := NewStaticKeyProvider("v2", sources)
+	if err != nil {
+		t.Fatalf("NewStaticKeyProvider: %v", err)
+	}
+	encrypter := NewEncrypter(keys)
+	if got, err := encrypter.Decrypt(sealed, aad); err != nil || string(got) != "4111111111111111" {
+		t.Fatalf("Decrypt under old key after rotation = %q, %v", got, err)
+	}
+
+	// Перешифрование при ротации: расшифровка старым ключом и шифрование активным
+	plaintext, err := encrypter.Decrypt(sealed, aad)
+	if err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	rotated, err := encrypter.Encrypt(plaintext, aad)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if rotated.KeyID != "v2" {
+		t.Fatalf("Encrypt after rotation KeyID = %q, want v2", rotated.KeyID)
+	}
+
+	// После вывода v1 из конфигурации перешифрованные данные расшифровываются, старые - нет
+	delete(sources, "v1")
+	retired, err := NewStaticKeyProvider("v2", sources)
+	if err != nil {
+		t.Fatalf("NewStaticKeyProvider: %v", err)
+	}
+	if got, err := NewEncrypter(retired).Decrypt(rotated, aad); err != nil || string(got) != "4111111111111111" {
+		t.Fatalf("Decrypt rotated after retiring v1 = %q, %v", got, err)
+	}
+	if _, err = NewEncrypter(retired).Decrypt(sealed, aad); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("Decrypt under retired key: err = %v, want ErrUnknownKey", err)
+	}
+}
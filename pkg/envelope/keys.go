@@ -0,0 +1,99 @@
+package envelope
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+const keySize = 32
+
+// envKeyPrefix - ключ берется из переменной окружения, а не из файла
+const envKeyPrefix = "env:"
+
+var ErrUnknownKey = errors.New("unknown master key")
+
+// KeyProvider - источник мастер-ключей, которыми шифруются ключи данных
+type KeyProvider interface {
+	// ActiveKeyID - ключ, которым шифруются новые данные
+	ActiveKeyID() string
+	// MasterKey - мастер-ключ по его id, в том числе выведенный из ротации
+	MasterKey(keyID string) ([]byte, error)
+}
+
+// StaticKeyProvider - мастер-ключи, загруженные при старте из переменных окружения или файлов секретов
+type StaticKeyProvider struct {
+	activeKeyID string
+	keys        map[string][]byte
+}
+
+// NewStaticKeyProvider загружает мастер-ключи, sources - id ключа -> источник ключа (см. LoadKey)
+func NewStaticKeyProvider(activeKeyID string, sources map[string]string) (*StaticKeyProvider, error) {
+	keys := make(map[string][]byte, len(sources))
+	for keyID, source := range sources {
+		key, err := LoadKey(source)
+		if err != nil {
+			logger.WithError(err).Errorf("failed to load master key %s", keyID)
+			return nil, fmt.Errorf("failed to load master key %s: %w", keyID, err)
+		}
+		keys[keyID] = key
+	}
+
+	if _, ok := keys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("active master key %q is not configured", activeKeyID)
+	}
+
+	return &StaticKeyProvider{
+		activeKeyID: activeKeyID,
+		keys:        keys,
+	}, nil
+}
+
+// LoadKey читает 32-байтный ключ в base64 из источника: "env:ИМЯ" - из переменной окружения,
+// иначе из файла по указанному пути (например, секрета, смонтированного в /run/secrets)
+func LoadKey(source string) ([]byte, error) {
+	if name, ok := strings.CutPrefix(source, envKeyPrefix); ok {
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			return nil, fmt.Errorf("environment variable %s is not set", name)
+		}
+		return decodeKey(value)
+	}
+	return LoadKeyFile(source)
+}
+
+// LoadKeyFile читает 32-байтный ключ, записанный в файл в base64
+func LoadKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeKey(string(data))
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", keySize, len(key))
+	}
+	return key, nil
+}
+
+func (p *StaticKeyProvider) ActiveKeyID() string {
+	return p.activeKeyID
+}
+
+func (p *StaticKeyProvider) MasterKey(keyID string) ([]byte, error) {
+	key, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+	return key, nil
+}
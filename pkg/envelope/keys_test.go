@@ -0,0 +1,60 @@
+package envelope
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadKey(t *testing.T) {
+	key := randomKey(t)
+	encoded := base64.StdEncoding.EncodeToString(key)
+
+	t.Setenv("ENVELOPE_TEST_KEY", encoded)
+	path := filepath.Join(t.TempDir(), "card-master-key")
+	// Секреты обычно записываются с переводом строки в конце
+	if err := os.WriteFile(path, []byte(encoded+"\n"), 0o600); err != nil {
+		t.Fatalf("write key file: %v", err)
+	}
+
+	for _, source := range []string{"env:ENVELOPE_TEST_KEY", path} {
+		got, err := LoadKey(source)
+		if err != nil {
+			t.Fatalf("LoadKey(%q): %v", source, err)
+		}
+		if !bytes.Equal(got, key) {
+			t.Fatalf("LoadKey(%q) returned a different key", source)
+		}
+	}
+}
+
+func TestLoadKeyRejectsInvalid(t *testing.T) {
+	t.Setenv("ENVELOPE_TEST_EMPTY", "")
+	t.Setenv("ENVELOPE_TEST_SHORT", base64.StdEncoding.EncodeToString([]byte("too short")))
+	t.Setenv("ENVELOPE_TEST_NOT_BASE64", "not base64!")
+
+	for _, source := range []string{
+		"env:ENVELOPE_TEST_UNSET",
+		"env:ENVELOPE_TEST_EMPTY",
+		"env:ENVELOPE_TEST_SHORT",
+		"env:ENVELOPE_TEST_NOT_BASE64",
+		filepath.Join(t.TempDir(), "missing"),
+	} {
+		if _, err := LoadKey(source); err == nil {
+			t.Fatalf("LoadKey(%q) accepted an invalid key", source)
+		}
+	}
+}
+
+func TestStaticKeyProviderRequiresActiveKey(t *testing.T) {
+	t.Setenv("ENVELOPE_TEST_KEY", base64.StdEncoding.EncodeToString(randomKey(t)))
+
+	if _, err := NewStaticKeyProvider("v2", map[string]string{"v1": "env:ENVELOPE_TEST_KEY"}); err == nil {
+		t.Fatal("NewStaticKeyProvider accepted an active key that is not configured")
+	}
+	if _, err := NewStaticKeyProvider("v1", map[string]string{"v1": "env:ENVELOPE_TEST_UNSET"}); err == nil {
+		t.Fatal("NewStaticKeyProvider accepted a key that failed to load")
+	}
+}